@@ -3,3 +3,8 @@ package common
 type ErrorResponse struct {
 	Error string `json:"error" example:"error message"`
 }
+
+type ConflictResponse struct {
+	Error   string      `json:"error" example:"version conflict"`
+	Current interface{} `json:"current"`
+}
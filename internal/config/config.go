@@ -6,6 +6,8 @@ import (
 	"os"
 	"path/filepath"
 	"runtime"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/joho/godotenv"
@@ -19,6 +21,20 @@ var DB *mongo.Database
 var GoogleClientID string
 var GoogleClientSecret string
 var JWTSecret []byte
+var AccessTokenTTL time.Duration
+var RefreshTokenTTL time.Duration
+var OAuthRedirectBaseURL string
+var OIDCProviders []OIDCProvider
+var TrashRetention time.Duration
+var NoteRevisionLimit int
+
+type OIDCProvider struct {
+	Name         string
+	IssuerURL    string
+	ClientID     string
+	ClientSecret string
+	Scopes       []string
+}
 
 func envPath() string {
 	_, b, _, _ := runtime.Caller(0)
@@ -27,6 +43,74 @@ func envPath() string {
 	return envPath
 }
 
+func durationEnv(key string, fallback time.Duration) time.Duration {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %s", key, value, fallback)
+		return fallback
+	}
+
+	return duration
+}
+
+func intEnv(key string, fallback int) int {
+	value := os.Getenv(key)
+	if value == "" {
+		return fallback
+	}
+
+	number, err := strconv.Atoi(value)
+	if err != nil {
+		log.Printf("Invalid %s %q, using %d", key, value, fallback)
+		return fallback
+	}
+
+	return number
+}
+
+func loadOIDCProviders() []OIDCProvider {
+	providers := []OIDCProvider{}
+
+	if GoogleClientID != "" {
+		providers = append(providers, OIDCProvider{
+			Name:         "google",
+			IssuerURL:    "https://accounts.google.com",
+			ClientID:     GoogleClientID,
+			ClientSecret: GoogleClientSecret,
+		})
+	}
+
+	for _, name := range strings.Split(os.Getenv("OIDC_PROVIDERS"), ",") {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if name == "" {
+			continue
+		}
+
+		prefix := "OIDC_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		provider := OIDCProvider{
+			Name:         name,
+			IssuerURL:    os.Getenv(prefix + "ISSUER"),
+			ClientID:     os.Getenv(prefix + "CLIENT_ID"),
+			ClientSecret: os.Getenv(prefix + "CLIENT_SECRET"),
+			Scopes:       strings.FieldsFunc(os.Getenv(prefix+"SCOPES"), func(r rune) bool { return r == ',' || r == ' ' }),
+		}
+
+		if provider.IssuerURL == "" || provider.ClientID == "" {
+			log.Printf("Skipping OIDC provider %s: %sISSUER and %sCLIENT_ID are required", name, prefix, prefix)
+			continue
+		}
+
+		providers = append(providers, provider)
+	}
+
+	return providers
+}
+
 func LoadConfig() {
 	path := envPath()
 	err := godotenv.Load(path)
@@ -42,11 +126,21 @@ func LoadConfig() {
 	GoogleClientID = os.Getenv("GOOGLE_CLIENT_ID")
 	GoogleClientSecret = os.Getenv("GOOGLE_CLIENT_SECRET")
 	JWTSecret = []byte(os.Getenv("JWT_SECRET"))
+	AccessTokenTTL = durationEnv("ACCESS_TOKEN_TTL", 15*time.Minute)
+	RefreshTokenTTL = durationEnv("REFRESH_TOKEN_TTL", 30*24*time.Hour)
+	OAuthRedirectBaseURL = strings.TrimSuffix(os.Getenv("OAUTH_REDIRECT_BASE_URL"), "/")
+	OIDCProviders = loadOIDCProviders()
+	TrashRetention = durationEnv("TRASH_RETENTION", 30*24*time.Hour)
+	NoteRevisionLimit = intEnv("NOTE_REVISION_LIMIT", 50)
 
 	if AllowedOrigins == "" {
 		AllowedOrigins = "*"
 	}
 
+	if OAuthRedirectBaseURL == "" {
+		OAuthRedirectBaseURL = "http://localhost:8080"
+	}
+
 	ConnectMongoDB(mongoURI, dbName)
 }
 
@@ -1,8 +1,12 @@
 package middleware
 
 import (
+	"context"
 	"net/http"
 	"noto/internal/config"
+	"noto/internal/services/auth/repository"
+	token_repository "noto/internal/services/tokens/repository"
+	token_service "noto/internal/services/tokens/service"
 	"strings"
 
 	"github.com/gofiber/fiber/v2"
@@ -22,6 +26,10 @@ func Protected(c *fiber.Ctx) error {
 
 	tokenString := parts[1]
 
+	if token_service.IsPersonalToken(tokenString) {
+		return personalToken(c, tokenString)
+	}
+
 	token, err := jwt.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
 		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
 			return nil, fiber.NewError(http.StatusUnauthorized, "invalid signing method")
@@ -38,10 +46,59 @@ func Protected(c *fiber.Ctx) error {
 		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "invalid token claims"})
 	}
 
+	// Every token the server signs shares the secret and carries a typ, so
+	// only tokens explicitly typed as access tokens are accepted.
+	if claims["typ"] != "access" {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
+	}
+
+	jti, _ := claims["jti"].(string)
+	sid, _ := claims["sid"].(string)
+
+	revoked, err := repository.NewAuthRepository(config.DB).IsTokenRevoked(context.Background(), jti, sid)
+	if err != nil {
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "failed to verify token"})
+	}
+	if revoked {
+		return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "token has been revoked"})
+	}
+
 	c.Locals("userID", claims["id"])
 	c.Locals("userEmail", claims["email"])
 	c.Locals("userName", claims["name"])
 	c.Locals("jwtExp", claims["exp"])
+	c.Locals("jwtID", jti)
+	c.Locals("sessionID", sid)
+
+	if rawScopes, ok := claims["scopes"].([]interface{}); ok {
+		scopes := []string{}
+		for _, scope := range rawScopes {
+			if value, ok := scope.(string); ok {
+				scopes = append(scopes, value)
+			}
+		}
+		c.Locals("scopes", scopes)
+	}
+
+	return c.Next()
+}
+
+func personalToken(c *fiber.Ctx, tokenString string) error {
+	tokenService := token_service.NewTokenService(token_repository.NewTokenRepository(config.DB))
+
+	token, err := tokenService.Authenticate(tokenString)
+	if err != nil {
+		if err.Error() == "token not found" {
+			return c.Status(http.StatusUnauthorized).JSON(fiber.Map{"error": "invalid or expired token"})
+		}
+		return c.Status(http.StatusInternalServerError).JSON(fiber.Map{"error": "failed to verify token"})
+	}
+
+	c.Locals("userID", token.UserId.Hex())
+	c.Locals("tokenID", token.ID.Hex())
+	if len(token.Scopes) > 0 {
+		c.Locals("scopes", token.Scopes)
+	}
 
 	return c.Next()
 }
@@ -0,0 +1,34 @@
+package middleware
+
+import (
+	"noto/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func RequireScope(scopes ...string) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		granted, ok := c.Locals("scopes").([]string)
+		if !ok {
+			return c.Next()
+		}
+
+		bookScoped := false
+		for _, scope := range scopes {
+			if !utils.HasScope(granted, scope) {
+				return utils.ErrorForbidden(c, "missing scope: "+scope)
+			}
+			if strings.HasPrefix(scope, "books:") || strings.HasPrefix(scope, "notes:") {
+				bookScoped = true
+			}
+		}
+
+		bookId := c.Params("bookId")
+		if (bookId != "" || bookScoped) && !utils.HasBookAccess(granted, bookId) {
+			return utils.ErrorForbidden(c, "token is not allowed to access this book")
+		}
+
+		return c.Next()
+	}
+}
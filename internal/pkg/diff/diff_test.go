@@ -0,0 +1,106 @@
+package diff
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func apply(a []string, ops []Op) ([]string, []string) {
+	from, to := []string{}, []string{}
+	for _, op := range ops {
+		if op.Kind != Insert {
+			from = append(from, op.Text)
+		}
+		if op.Kind != Delete {
+			to = append(to, op.Text)
+		}
+	}
+	return from, to
+}
+
+func TestLines(t *testing.T) {
+	testCases := []struct {
+		name    string
+		a       string
+		b       string
+		changes int
+	}{
+		{name: "Identical", a: "a b c", b: "a b c", changes: 0},
+		{name: "Insert", a: "a c", b: "a b c", changes: 1},
+		{name: "Delete", a: "a b c", b: "a c", changes: 1},
+		{name: "Replace", a: "a b c", b: "a x c", changes: 2},
+		{name: "From Empty", a: "", b: "a b", changes: 2},
+		{name: "To Empty", a: "a b", b: "", changes: 2},
+		{name: "Both Empty", a: "", b: "", changes: 0},
+		{name: "Shuffled", a: "a b c a b b a", b: "c b a b a c", changes: 5},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			a, b := strings.Fields(testCase.a), strings.Fields(testCase.b)
+			ops := Lines(a, b)
+
+			from, to := apply(a, ops)
+			assert.Equal(t, append([]string{}, a...), from)
+			assert.Equal(t, append([]string{}, b...), to)
+
+			changes := 0
+			for _, op := range ops {
+				if op.Kind != Equal {
+					changes++
+				}
+			}
+			assert.Equal(t, testCase.changes, changes)
+		})
+	}
+}
+
+func TestUnified(t *testing.T) {
+	testCases := []struct {
+		name     string
+		from     string
+		to       string
+		expected string
+	}{
+		{
+			name:     "Identical",
+			from:     "one\ntwo\n",
+			to:       "one\ntwo\n",
+			expected: "",
+		},
+		{
+			name: "Single Change",
+			from: "one\ntwo\nthree\n",
+			to:   "one\n2\nthree\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -1,3 +1,3 @@\n" +
+				" one\n-two\n+2\n three\n",
+		},
+		{
+			name: "Separate Hunks",
+			from: "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n",
+			to:   "x\n2\n3\n4\n5\n6\n7\n8\n9\ny\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -1,2 +1,2 @@\n" +
+				"-1\n+x\n 2\n" +
+				"@@ -9,2 +9,2 @@\n" +
+				" 9\n-10\n+y\n",
+		},
+		{
+			name: "From Empty",
+			from: "",
+			to:   "hello\n",
+			expected: "--- a\n+++ b\n" +
+				"@@ -0,0 +1 @@\n" +
+				"+hello\n",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, Unified("a", "b", testCase.from, testCase.to, 1))
+		})
+	}
+}
@@ -0,0 +1,142 @@
+// Package rank generates rank strings for manual ordering. Ranks compare
+// lexicographically, and a new rank can always be placed between two others,
+// so moving an item only rewrites that item.
+package rank
+
+import (
+	"errors"
+	"strings"
+)
+
+const digits = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+const base = len(digits)
+
+// Between returns a rank that sorts after a and before b. An empty a means
+// the start of the list and an empty b the end.
+func Between(a string, b string) (string, error) {
+	if !valid(a) || !valid(b) {
+		return "", errors.New("invalid rank")
+	}
+
+	if b != "" && a >= b {
+		return "", errors.New("ranks are out of order")
+	}
+
+	return midpoint(a, b), nil
+}
+
+// Spread returns n ranks in ascending order, evenly spaced so that later
+// insertions stay short.
+func Spread(n int) []string {
+	width := 1
+	for capacity := base; capacity <= n; capacity *= base {
+		width++
+	}
+
+	space := 1
+	for i := 0; i < width; i++ {
+		space *= base
+	}
+
+	ranks := make([]string, n)
+	for i := range ranks {
+		ranks[i] = encode((i+1)*space/(n+1), width)
+	}
+
+	return ranks
+}
+
+// SpreadBetween returns n ascending ranks that all sort after a and before b,
+// splitting the gap in halves so the ranks only grow with log n.
+func SpreadBetween(a string, b string, n int) ([]string, error) {
+	if n == 0 {
+		return []string{}, nil
+	}
+
+	middle, err := Between(a, b)
+	if err != nil {
+		return nil, err
+	}
+
+	below, err := SpreadBetween(a, middle, n/2)
+	if err != nil {
+		return nil, err
+	}
+
+	above, err := SpreadBetween(middle, b, n-n/2-1)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(append(below, middle), above...), nil
+}
+
+// midpoint expects a < b, with an empty b standing for the end of the list.
+func midpoint(a string, b string) string {
+	if b != "" {
+		n := 0
+		for n < len(b) && digitAt(a, n) == b[n] {
+			n++
+		}
+		if n > 0 {
+			return b[:n] + midpoint(trim(a, n), b[n:])
+		}
+	}
+
+	low := 0
+	if a != "" {
+		low = strings.IndexByte(digits, a[0])
+	}
+
+	high := base
+	if b != "" {
+		high = strings.IndexByte(digits, b[0])
+	}
+
+	if high-low > 1 {
+		return string(digits[(low+high)/2])
+	}
+
+	if b != "" && len(b) > 1 {
+		return b[:1]
+	}
+
+	return string(digits[low]) + midpoint(trim(a, 1), "")
+}
+
+func digitAt(s string, i int) byte {
+	if i < len(s) {
+		return s[i]
+	}
+	return digits[0]
+}
+
+func trim(s string, n int) string {
+	if n >= len(s) {
+		return ""
+	}
+	return s[n:]
+}
+
+func encode(value int, width int) string {
+	encoded := make([]byte, width)
+	for i := width - 1; i >= 0; i-- {
+		encoded[i] = digits[value%base]
+		value /= base
+	}
+
+	return strings.TrimRight(string(encoded), digits[:1])
+}
+
+// valid rejects characters outside the alphabet and trailing zeros, since no
+// rank would fit between "A" and "A0".
+func valid(rank string) bool {
+	for i := 0; i < len(rank); i++ {
+		if strings.IndexByte(digits, rank[i]) < 0 {
+			return false
+		}
+	}
+
+	return !strings.HasSuffix(rank, digits[:1])
+}
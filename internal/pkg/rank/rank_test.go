@@ -0,0 +1,124 @@
+package rank
+
+import (
+	"math/rand"
+	"sort"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBetween(t *testing.T) {
+	testCases := []struct {
+		name          string
+		a             string
+		b             string
+		expectedError string
+	}{
+		{name: "Empty List", a: "", b: ""},
+		{name: "At Start", a: "", b: "V"},
+		{name: "At End", a: "V", b: ""},
+		{name: "Adjacent Digits", a: "A", b: "B"},
+		{name: "Common Prefix", a: "AB", b: "AC"},
+		{name: "Prefix Of Other", a: "A", b: "A1"},
+		{name: "Before Smallest", a: "", b: "01"},
+		{name: "After Largest", a: "zz", b: ""},
+		{name: "Out Of Order", a: "B", b: "A", expectedError: "ranks are out of order"},
+		{name: "Equal", a: "A", b: "A", expectedError: "ranks are out of order"},
+		{name: "Trailing Zero", a: "A0", b: "", expectedError: "invalid rank"},
+		{name: "Invalid Character", a: "a-b", b: "", expectedError: "invalid rank"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			rank, err := Between(testCase.a, testCase.b)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.True(t, valid(rank), "rank %q should be valid", rank)
+			assert.Greater(t, rank, testCase.a)
+			if testCase.b != "" {
+				assert.Less(t, rank, testCase.b)
+			}
+		})
+	}
+}
+
+func TestBetweenRepeated(t *testing.T) {
+	random := rand.New(rand.NewSource(1))
+	ranks := []string{}
+
+	for i := 0; i < 1000; i++ {
+		position := random.Intn(len(ranks) + 1)
+
+		before, after := "", ""
+		if position > 0 {
+			before = ranks[position-1]
+		}
+		if position < len(ranks) {
+			after = ranks[position]
+		}
+
+		rank, err := Between(before, after)
+		require.NoError(t, err)
+
+		ranks = append(ranks[:position], append([]string{rank}, ranks[position:]...)...)
+	}
+
+	assert.True(t, sort.StringsAreSorted(ranks))
+}
+
+func TestSpread(t *testing.T) {
+	for _, n := range []int{0, 1, 2, 61, 62, 1000} {
+		ranks := Spread(n)
+		require.Len(t, ranks, n)
+
+		for i, rank := range ranks {
+			assert.True(t, valid(rank), "rank %q should be valid", rank)
+			if i > 0 {
+				assert.Less(t, ranks[i-1], rank)
+			}
+		}
+	}
+}
+
+func TestSpreadBetween(t *testing.T) {
+	testCases := []struct {
+		name string
+		a    string
+		b    string
+		n    int
+	}{
+		{name: "Empty", a: "", b: "", n: 0},
+		{name: "Whole List", a: "", b: "", n: 10},
+		{name: "Before First", a: "", b: "V", n: 500},
+		{name: "Narrow Gap", a: "V", b: "W", n: 100},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			ranks, err := SpreadBetween(testCase.a, testCase.b, testCase.n)
+			require.NoError(t, err)
+			require.Len(t, ranks, testCase.n)
+
+			for i, rank := range ranks {
+				assert.True(t, valid(rank), "rank %q should be valid", rank)
+				assert.Greater(t, rank, testCase.a)
+				if testCase.b != "" {
+					assert.Less(t, rank, testCase.b)
+				}
+				if i > 0 {
+					assert.Less(t, ranks[i-1], rank)
+				}
+				assert.LessOrEqual(t, len(rank), 12)
+			}
+		})
+	}
+
+	_, err := SpreadBetween("W", "V", 1)
+	assert.EqualError(t, err, "ranks are out of order")
+}
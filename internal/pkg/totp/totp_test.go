@@ -0,0 +1,81 @@
+package totp
+
+import (
+	"encoding/base32"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Test vectors from RFC 6238 appendix B, truncated to six digits.
+var rfcSecret = base32.StdEncoding.WithPadding(base32.NoPadding).EncodeToString([]byte("12345678901234567890"))
+
+func TestCode(t *testing.T) {
+	testCases := []struct {
+		name     string
+		unix     int64
+		expected string
+	}{
+		{name: "T=59", unix: 59, expected: "287082"},
+		{name: "T=1111111109", unix: 1111111109, expected: "081804"},
+		{name: "T=1111111111", unix: 1111111111, expected: "050471"},
+		{name: "T=1234567890", unix: 1234567890, expected: "005924"},
+		{name: "T=2000000000", unix: 2000000000, expected: "279037"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			code, err := Code(rfcSecret, Step(time.Unix(testCase.unix, 0)))
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, code)
+		})
+	}
+}
+
+func TestValidate(t *testing.T) {
+	now := time.Unix(1111111109, 0)
+
+	testCases := []struct {
+		name     string
+		code     string
+		at       time.Time
+		expected bool
+	}{
+		{name: "Current Step", code: "081804", at: now, expected: true},
+		{name: "Previous Step Drift", code: "081804", at: now.Add(Period * time.Second), expected: true},
+		{name: "Too Old", code: "081804", at: now.Add(3 * Period * time.Second), expected: false},
+		{name: "Wrong Code", code: "123456", at: now, expected: false},
+		{name: "Wrong Length", code: "0818", at: now, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			step, ok := Validate(rfcSecret, testCase.code, testCase.at)
+			assert.Equal(t, testCase.expected, ok)
+			if ok {
+				assert.Equal(t, Step(now), step)
+			}
+		})
+	}
+}
+
+func TestGenerateSecret(t *testing.T) {
+	secret, err := GenerateSecret()
+	require.NoError(t, err)
+
+	other, err := GenerateSecret()
+	require.NoError(t, err)
+
+	assert.Len(t, secret, 32)
+	assert.NotEqual(t, secret, other)
+
+	_, err = Code(secret, 1)
+	assert.NoError(t, err)
+}
+
+func TestURI(t *testing.T) {
+	uri := URI("Noto", "user@example.com", "JBSWY3DPEHPK3PXP")
+	assert.Equal(t, "otpauth://totp/Noto:user@example.com?algorithm=SHA1&digits=6&issuer=Noto&period=30&secret=JBSWY3DPEHPK3PXP", uri)
+}
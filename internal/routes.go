@@ -4,8 +4,13 @@ import (
 	"noto/internal/middleware"
 	auth_router "noto/internal/services/auth"
 	books_router "noto/internal/services/books"
+	export_router "noto/internal/services/export"
 	labels_router "noto/internal/services/labels"
 	notes_router "noto/internal/services/notes"
+	search_router "noto/internal/services/search"
+	tokens_router "noto/internal/services/tokens"
+	trash_router "noto/internal/services/trash"
+	users_router "noto/internal/services/users"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -20,4 +25,9 @@ func SetupRoutes(app *fiber.App) {
 	books_router.BooksRouter(protected)
 	notes_router.NotesRouter(protected)
 	labels_router.LabelsRouter(protected)
+	tokens_router.TokensRouter(protected)
+	users_router.UsersRouter(protected)
+	export_router.ExportRouter(protected)
+	search_router.SearchRouter(protected)
+	trash_router.TrashRouter(protected)
 }
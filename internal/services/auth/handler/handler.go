@@ -2,13 +2,17 @@ package handler
 
 import (
 	_ "noto/internal/common"
-	_ "noto/internal/services/auth/model"
+	"noto/internal/services/auth/model"
 	"noto/internal/services/auth/service"
 	"noto/internal/utils"
+	"strings"
+	"time"
 
 	"github.com/gofiber/fiber/v2"
 )
 
+const oauthStateCookie = "noto_oauth_state"
+
 type AuthHandler struct {
 	authService service.AuthService
 }
@@ -17,34 +21,472 @@ func NewAuthHandler(authService service.AuthService) *AuthHandler {
 	return &AuthHandler{authService: authService}
 }
 
-// HandleGoogleLogin godoc
-// @Summary Initiate Google OAuth login
-// @Description Redirects the user to Google's OAuth consent screen
+// HandleLogin godoc
+// @Summary Initiate OpenID Connect login
+// @Description Redirects the user to the consent screen of the configured provider, e.g. google
 // @Tags Auth
 // @Produce json
-// @Success 	302 	{string} string "Redirect to Google's OAuth consent screen"
-// @Router /auth/google [get]
-func (h *AuthHandler) HandleGoogleLogin(c *fiber.Ctx) error {
-	url := h.authService.HandleGoogleLogin()
-	return c.Redirect(url)
+// @Param provider path string true "Provider name"
+// @Success 	302 	{string} string "Redirect to the provider's consent screen"
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/{provider} [get]
+func (h *AuthHandler) HandleLogin(c *fiber.Ctx) error {
+	login, err := h.authService.HandleLogin(c.Params("provider"))
+	if err != nil {
+		if err.Error() == "provider not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Value:    login.StateToken,
+		Path:     "/auth",
+		Expires:  time.Now().Add(service.OAuthStateTTL),
+		HTTPOnly: true,
+		Secure:   c.Protocol() == "https",
+		SameSite: fiber.CookieSameSiteLaxMode,
+	})
+
+	return c.Redirect(login.URL)
 }
 
-// HandleGoogleCallback godoc
-// @Summary Handle Google OAuth callback
-// @Description Processes the OAuth code returned by Google and returns a token
+// HandleCallback godoc
+// @Summary Handle OpenID Connect callback
+// @Description Processes the authorization code returned by the provider and returns a token. When the verified email belongs to an account with a password, link_required and a link_token are returned instead, to confirm at /auth/link, and accounts with two-factor authentication get mfa_required and an mfa_token to complete with /auth/mfa.
 // @Tags Auth
 // @Accept json
 // @Produce json
-// @Param code query string true "The authorization code returned by Google"
+// @Param provider path string true "Provider name"
+// @Param code query string true "The authorization code returned by the provider"
+// @Param state query string true "The state issued by /auth/{provider}"
 // @Success 	200 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
-// @Router /auth/google/callback [get]
-func (h *AuthHandler) HandleGoogleCallback(c *fiber.Ctx) error {
+// @Router /auth/{provider}/callback [get]
+func (h *AuthHandler) HandleCallback(c *fiber.Ctx) error {
 	code := c.Query("code")
-	token, err := h.authService.HandleGoogleCallback(code)
+	state := c.Query("state")
+	stateToken := c.Cookies(oauthStateCookie)
+	c.Cookie(&fiber.Cookie{
+		Name:     oauthStateCookie,
+		Path:     "/auth",
+		Expires:  time.Now().Add(-time.Hour),
+		HTTPOnly: true,
+	})
+
+	token, err := h.authService.HandleCallback(c.Params("provider"), code, state, stateToken)
 	if err != nil {
+		switch {
+		case err.Error() == "provider not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case err.Error() == "invalid oauth state", err.Error() == "missing authorization code",
+			err.Error() == "provider did not return an email address", err.Error() == "provider did not return a subject":
+			return utils.ErrorBadRequest(c, err.Error())
+		case err.Error() == "email address is not verified":
+			return utils.ErrorForbidden(c, err.Error())
+		case strings.HasPrefix(err.Error(), "invalid id token"):
+			return utils.ErrorUnauthorized(c, err.Error())
+		case err.Error() == "identity already linked to another account":
+			return utils.ErrorConflict(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, err.Error())
 	}
 
 	return c.JSON(token)
 }
+
+// Register godoc
+// @Summary Register a local account
+// @Description Creates an account with email and password and returns a token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param		user	body		model.Register	true	"Account to register"
+// @Success 	201 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/register [post]
+func (h *AuthHandler) Register(c *fiber.Ctx) error {
+	register := new(model.Register)
+	if err := c.BodyParser(register); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token, err := h.authService.Register(register)
+	if err != nil {
+		switch err.Error() {
+		case "email already registered":
+			return utils.ErrorConflict(c, err.Error())
+		case "invalid email address", "name is required",
+			"password must be at least 8 characters", "password must be at most 72 characters":
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to register: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(token)
+}
+
+// Login godoc
+// @Summary Login with email and password
+// @Description Authenticates a local account and returns a token. Accounts with two-factor authentication get mfa_required and an mfa_token to complete with /auth/mfa instead.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param		credentials	body		model.Login	true	"Account credentials"
+// @Success 	200 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/login [post]
+func (h *AuthHandler) Login(c *fiber.Ctx) error {
+	login := new(model.Login)
+	if err := c.BodyParser(login); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token, err := h.authService.Login(login)
+	if err != nil {
+		if err.Error() == "invalid email or password" {
+			return utils.ErrorUnauthorized(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to login: "+err.Error())
+	}
+
+	return c.JSON(token)
+}
+
+// LinkAccount godoc
+// @Summary Link a provider account
+// @Description Confirms linking a provider account to an existing account with its password, using the link_token returned by the provider callback, and returns a token
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param		link	body		model.LinkAccount	true	"Link token and account password"
+// @Success 	200 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/link [post]
+func (h *AuthHandler) LinkAccount(c *fiber.Ctx) error {
+	link := new(model.LinkAccount)
+	if err := c.BodyParser(link); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token, err := h.authService.LinkAccount(link)
+	if err != nil {
+		switch err.Error() {
+		case "invalid link token", "current password is incorrect":
+			return utils.ErrorUnauthorized(c, err.Error())
+		case "identity already linked to another account":
+			return utils.ErrorConflict(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to link account: "+err.Error())
+	}
+
+	return c.JSON(token)
+}
+
+// ChangePassword godoc
+// @Summary Change password
+// @Description Changes the password of the current user and signs out every other session. Accounts without a password can set one within ten minutes of signing in.
+// @Tags Auth
+// @Security 	BearerAuth
+// @Accept json
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		password	body		model.ChangePassword	true	"Current and new password"
+// @Success		200		{object} 	interface{}
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/password [put]
+func (h *AuthHandler) ChangePassword(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	password := new(model.ChangePassword)
+	if err := c.BodyParser(password); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	claims := &model.TokenClaims{UserId: userId}
+	claims.SessionId, _ = c.Locals("sessionID").(string)
+
+	if err := h.authService.ChangePassword(claims, password); err != nil {
+		switch err.Error() {
+		case "current password is incorrect":
+			return utils.ErrorUnauthorized(c, err.Error())
+		case "sign in again to set a password":
+			return utils.ErrorForbidden(c, err.Error())
+		case "user not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case "password must be at least 8 characters", "password must be at most 72 characters":
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to change password: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "password changed",
+	})
+}
+
+// Refresh godoc
+// @Summary Refresh access token
+// @Description Exchanges a refresh token for a new access token. The refresh token is rotated and the old one stops working.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param		token	body		model.RefreshToken	true	"Refresh token"
+// @Success 	200 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/refresh [post]
+func (h *AuthHandler) Refresh(c *fiber.Ctx) error {
+	refresh := new(model.RefreshToken)
+	if err := c.BodyParser(refresh); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token, err := h.authService.Refresh(refresh.RefreshToken)
+	if err != nil {
+		if err.Error() == "invalid refresh token" {
+			return utils.ErrorUnauthorized(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to refresh token: "+err.Error())
+	}
+
+	return c.JSON(token)
+}
+
+// Logout godoc
+// @Summary Logout
+// @Description Revokes the current token and its session. Set all to true to sign out of every session.
+// @Tags Auth
+// @Security 	BearerAuth
+// @Accept json
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		logout	body		model.Logout	false	"Logout options"
+// @Success		200		{object} 	interface{}
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/logout [post]
+func (h *AuthHandler) Logout(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	logout := new(model.Logout)
+	if len(c.Body()) > 0 {
+		if err := c.BodyParser(logout); err != nil {
+			return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+		}
+	}
+
+	claims := &model.TokenClaims{UserId: userId}
+	claims.TokenId, _ = c.Locals("jwtID").(string)
+	claims.SessionId, _ = c.Locals("sessionID").(string)
+	if exp, ok := c.Locals("jwtExp").(float64); ok {
+		claims.ExpiresAt = time.Unix(int64(exp), 0)
+	}
+
+	if err := h.authService.Logout(claims, logout.All); err != nil {
+		return utils.ErrorInternalServer(c, "failed to logout: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "logged out",
+	})
+}
+
+func twoFactorError(c *fiber.Ctx, err error) error {
+	switch err.Error() {
+	case "invalid two-factor code", "current password is incorrect", "invalid mfa token":
+		return utils.ErrorUnauthorized(c, err.Error())
+	case "two-factor code is required", "two-factor authentication requires a password":
+		return utils.ErrorBadRequest(c, err.Error())
+	case "two-factor authentication is already enabled", "two-factor authentication is not enabled",
+		"two-factor enrollment not found":
+		return utils.ErrorConflict(c, err.Error())
+	case "too many two-factor attempts":
+		return utils.CustomError(c, fiber.StatusTooManyRequests, err.Error())
+	case "user not found":
+		return utils.ErrorNotFound(c, err.Error())
+	}
+	return utils.ErrorInternalServer(c, err.Error())
+}
+
+// VerifyMFA godoc
+// @Summary Complete a two-factor login
+// @Description Exchanges the mfa_token returned by a login and a TOTP or recovery code for a token. An mfa_token can be used once and is revoked after five wrong codes.
+// @Tags Auth
+// @Accept json
+// @Produce json
+// @Param		mfa	body		model.MFAVerify	true	"Challenge token and code"
+// @Success 	200 	{object} 	model.AuthToken
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     429     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/mfa [post]
+func (h *AuthHandler) VerifyMFA(c *fiber.Ctx) error {
+	verify := new(model.MFAVerify)
+	if err := c.BodyParser(verify); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token, err := h.authService.VerifyMFA(verify)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(token)
+}
+
+// EnrollTOTP godoc
+// @Summary Start TOTP enrollment
+// @Description Generates a new TOTP secret for the current user. Two-factor authentication is enabled once a code is confirmed with /auth/totp/verify.
+// @Tags Auth
+// @Security 	BearerAuth
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Success 	200 	{object} 	model.TOTPEnrollment
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/totp/enroll [post]
+func (h *AuthHandler) EnrollTOTP(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	enrollment, err := h.authService.EnrollTOTP(userId)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(enrollment)
+}
+
+// EnableTOTP godoc
+// @Summary Confirm TOTP enrollment
+// @Description Verifies a code from the authenticator app, enables two-factor authentication and returns recovery codes. Recovery codes are only shown once.
+// @Tags Auth
+// @Security 	BearerAuth
+// @Accept json
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		code	body		model.TOTPCode	true	"TOTP code"
+// @Success 	200 	{object} 	model.RecoveryCodes
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/totp/verify [post]
+func (h *AuthHandler) EnableTOTP(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	code := new(model.TOTPCode)
+	if err := c.BodyParser(code); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	recoveryCodes, err := h.authService.EnableTOTP(userId, code.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(recoveryCodes)
+}
+
+// RegenerateRecoveryCodes godoc
+// @Summary Regenerate recovery codes
+// @Description Replaces all recovery codes of the current user. Requires a valid TOTP code.
+// @Tags Auth
+// @Security 	BearerAuth
+// @Accept json
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		code	body		model.TOTPCode	true	"TOTP code"
+// @Success 	200 	{object} 	model.RecoveryCodes
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/totp/recovery-codes [post]
+func (h *AuthHandler) RegenerateRecoveryCodes(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	code := new(model.TOTPCode)
+	if err := c.BodyParser(code); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	recoveryCodes, err := h.authService.RegenerateRecoveryCodes(userId, code.Code)
+	if err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(recoveryCodes)
+}
+
+// DisableTOTP godoc
+// @Summary Disable two-factor authentication
+// @Description Requires the current password and either a TOTP code or a recovery code
+// @Tags Auth
+// @Security 	BearerAuth
+// @Accept json
+// @Produce json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		disable	body		model.TOTPDisable	true	"Password and code"
+// @Success		200		{object} 	interface{}
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router /auth/totp/disable [post]
+func (h *AuthHandler) DisableTOTP(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	disable := new(model.TOTPDisable)
+	if err := c.BodyParser(disable); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	if err := h.authService.DisableTOTP(userId, disable); err != nil {
+		return twoFactorError(c, err)
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "two-factor authentication disabled",
+	})
+}
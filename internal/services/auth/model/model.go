@@ -2,6 +2,8 @@ package model
 
 import (
 	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type User struct {
@@ -9,10 +11,109 @@ type User struct {
 	Email     string    `json:"email" bson:"email"`
 	Name      string    `json:"name" bson:"name"`
 	PhotoURL  string    `json:"photo_url" bson:"photoUrl"`
+	Password  string    `json:"-" bson:"password,omitempty"`
 	CreatedAt time.Time `json:"created_at" bson:"createdAt"`
 	UpdatedAt time.Time `json:"updated_at" bson:"updatedAt"`
+
+	// Identities are the provider accounts that can sign in as this user.
+	Identities []Identity `json:"-" bson:"identities,omitempty"`
+
+	TOTPEnabled       bool     `json:"totp_enabled" bson:"totpEnabled"`
+	TOTPSecret        string   `json:"-" bson:"totpSecret,omitempty"`
+	TOTPPendingSecret string   `json:"-" bson:"totpPendingSecret,omitempty"`
+	TOTPLastStep      int64    `json:"-" bson:"totpLastStep,omitempty"`
+	RecoveryCodes     []string `json:"-" bson:"recoveryCodes,omitempty"`
+}
+
+type Identity struct {
+	Provider string `json:"provider" bson:"provider"`
+	Subject  string `json:"subject" bson:"subject"`
 }
 
 type AuthToken struct {
-	Token string `json:"token"`
+	Token        string `json:"token,omitempty"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+	ExpiresAt    int64  `json:"expires_at,omitempty"`
+	MFARequired  bool   `json:"mfa_required,omitempty"`
+	MFAToken     string `json:"mfa_token,omitempty"`
+	LinkRequired bool   `json:"link_required,omitempty"`
+	LinkToken    string `json:"link_token,omitempty"`
+}
+
+type LinkAccount struct {
+	LinkToken string `json:"link_token"`
+	Password  string `json:"password"`
+}
+
+type MFAVerify struct {
+	MFAToken     string `json:"mfa_token"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type TOTPEnrollment struct {
+	Secret string `json:"secret"`
+	URI    string `json:"uri"`
+	QRCode string `json:"qr_code" example:"data:image/png;base64,..."`
+}
+
+type TOTPCode struct {
+	Code string `json:"code"`
+}
+
+type TOTPDisable struct {
+	Password     string `json:"password"`
+	Code         string `json:"code"`
+	RecoveryCode string `json:"recovery_code"`
+}
+
+type RecoveryCodes struct {
+	RecoveryCodes []string `json:"recovery_codes"`
+}
+
+type OAuthLogin struct {
+	URL        string
+	StateToken string
+}
+
+type Session struct {
+	ID                       primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId                   primitive.ObjectID `json:"user_id" bson:"userId"`
+	RefreshTokenHash         string             `json:"-" bson:"refreshTokenHash"`
+	PreviousRefreshTokenHash string             `json:"-" bson:"previousRefreshTokenHash,omitempty"`
+	CreatedAt                time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt                time.Time          `json:"updated_at" bson:"updatedAt"`
+	ExpiresAt                time.Time          `json:"expires_at" bson:"expiresAt"`
+	RevokedAt                *time.Time         `json:"revoked_at,omitempty" bson:"revokedAt,omitempty"`
+}
+
+type RefreshToken struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+type Logout struct {
+	All bool `json:"all"`
+}
+
+type TokenClaims struct {
+	UserId    primitive.ObjectID
+	TokenId   string
+	SessionId string
+	ExpiresAt time.Time
+}
+
+type Register struct {
+	Name     string `json:"name"`
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type Login struct {
+	Email    string `json:"email"`
+	Password string `json:"password"`
+}
+
+type ChangePassword struct {
+	CurrentPassword string `json:"current_password"`
+	NewPassword     string `json:"new_password"`
 }
@@ -0,0 +1,267 @@
+package provider
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type mockOIDC struct {
+	server   *httptest.Server
+	key      *rsa.PrivateKey
+	clientID string
+	claims   jwt.MapClaims
+}
+
+func newMockOIDC(t *testing.T) *mockOIDC {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	m := &mockOIDC{key: key, clientID: "noto"}
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(Discovery{
+			Issuer:                m.server.URL,
+			AuthorizationEndpoint: m.server.URL + "/authorize",
+			TokenEndpoint:         m.server.URL + "/token",
+			UserinfoEndpoint:      m.server.URL + "/userinfo",
+			JwksURI:               m.server.URL + "/jwks",
+		})
+	})
+	mux.HandleFunc("/jwks", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jsonWebKeySet{Keys: []jsonWebKey{{
+			Kid: "test-key",
+			Kty: "RSA",
+			Use: "sig",
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}}})
+	})
+	mux.HandleFunc("/token", func(w http.ResponseWriter, r *http.Request) {
+		r.ParseForm()
+		if r.Form.Get("code") != "valid-code" || r.Form.Get("code_verifier") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			json.NewEncoder(w).Encode(map[string]string{"error": "invalid_grant"})
+			return
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"access_token": "access-token",
+			"token_type":   "Bearer",
+			"id_token":     m.sign(t, m.claims, "test-key"),
+		})
+	})
+	mux.HandleFunc("/userinfo", func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer access-token" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		json.NewEncoder(w).Encode(UserInfo{Subject: "user-1", Email: "userinfo@example.com", Name: "From Userinfo"})
+	})
+
+	m.server = httptest.NewServer(mux)
+	t.Cleanup(m.server.Close)
+
+	return m
+}
+
+func (m *mockOIDC) sign(t *testing.T, claims jwt.MapClaims, kid string) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodRS256, claims)
+	token.Header["kid"] = kid
+
+	signed, err := token.SignedString(m.key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func (m *mockOIDC) validClaims(nonce string) jwt.MapClaims {
+	return jwt.MapClaims{
+		"iss":            m.server.URL,
+		"aud":            m.clientID,
+		"sub":            "user-1",
+		"email":          "user@example.com",
+		"email_verified": true,
+		"name":           "Test User",
+		"nonce":          nonce,
+		"exp":            time.Now().Add(time.Minute).Unix(),
+		"iat":            time.Now().Unix(),
+	}
+}
+
+func (m *mockOIDC) provider() *Provider {
+	return New(Config{
+		Name:         "mock",
+		IssuerURL:    m.server.URL,
+		ClientID:     m.clientID,
+		ClientSecret: "secret",
+		RedirectURL:  "http://localhost:8080/auth/mock/callback",
+	})
+}
+
+func TestAuthCodeURL(t *testing.T) {
+	m := newMockOIDC(t)
+
+	authURL, err := m.provider().AuthCodeURL(context.Background(), "state-value", "nonce-value", "verifier-value")
+	require.NoError(t, err)
+
+	parsed, err := url.Parse(authURL)
+	require.NoError(t, err)
+
+	query := parsed.Query()
+	assert.Equal(t, m.server.URL+"/authorize", parsed.Scheme+"://"+parsed.Host+parsed.Path)
+	assert.Equal(t, "state-value", query.Get("state"))
+	assert.Equal(t, "nonce-value", query.Get("nonce"))
+	assert.Equal(t, "S256", query.Get("code_challenge_method"))
+	assert.NotEmpty(t, query.Get("code_challenge"))
+	assert.Equal(t, "openid email profile", query.Get("scope"))
+	assert.Equal(t, "http://localhost:8080/auth/mock/callback", query.Get("redirect_uri"))
+}
+
+func TestExchange(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = m.validClaims("nonce-value")
+
+	userInfo, err := m.provider().Exchange(context.Background(), "valid-code", "verifier-value", "nonce-value")
+	require.NoError(t, err)
+
+	assert.Equal(t, "user-1", userInfo.Subject)
+	assert.Equal(t, "user@example.com", userInfo.Email)
+	assert.Equal(t, "Test User", userInfo.Name)
+	require.NotNil(t, userInfo.EmailVerified)
+	assert.True(t, *userInfo.EmailVerified)
+}
+
+func TestExchangeFallsBackToUserinfo(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = m.validClaims("nonce-value")
+	delete(m.claims, "email")
+
+	userInfo, err := m.provider().Exchange(context.Background(), "valid-code", "verifier-value", "nonce-value")
+	require.NoError(t, err)
+
+	assert.Equal(t, "userinfo@example.com", userInfo.Email)
+}
+
+func TestExchangeInvalidCode(t *testing.T) {
+	m := newMockOIDC(t)
+	m.claims = m.validClaims("nonce-value")
+
+	_, err := m.provider().Exchange(context.Background(), "invalid-code", "verifier-value", "nonce-value")
+	assert.Error(t, err)
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	m := newMockOIDC(t)
+	other, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		name          string
+		token         func() string
+		expectedError string
+	}{
+		{
+			name:          "Valid Token",
+			token:         func() string { return m.sign(t, m.validClaims("nonce-value"), "test-key") },
+			expectedError: "",
+		},
+		{
+			name: "Wrong Audience",
+			token: func() string {
+				claims := m.validClaims("nonce-value")
+				claims["aud"] = "someone-else"
+				return m.sign(t, claims, "test-key")
+			},
+			expectedError: "invalid id token audience",
+		},
+		{
+			name: "Wrong Issuer",
+			token: func() string {
+				claims := m.validClaims("nonce-value")
+				claims["iss"] = "https://evil.example.com"
+				return m.sign(t, claims, "test-key")
+			},
+			expectedError: "invalid id token issuer",
+		},
+		{
+			name:          "Wrong Nonce",
+			token:         func() string { return m.sign(t, m.validClaims("other-nonce"), "test-key") },
+			expectedError: "invalid id token nonce",
+		},
+		{
+			name: "Expired Token",
+			token: func() string {
+				claims := m.validClaims("nonce-value")
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return m.sign(t, claims, "test-key")
+			},
+			expectedError: "invalid id token",
+		},
+		{
+			name: "Wrong Signing Key",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodRS256, m.validClaims("nonce-value"))
+				token.Header["kid"] = "test-key"
+				signed, err := token.SignedString(other)
+				require.NoError(t, err)
+				return signed
+			},
+			expectedError: "invalid id token",
+		},
+		{
+			name: "HMAC Token",
+			token: func() string {
+				token := jwt.NewWithClaims(jwt.SigningMethodHS256, m.validClaims("nonce-value"))
+				signed, err := token.SignedString([]byte("secret"))
+				require.NoError(t, err)
+				return signed
+			},
+			expectedError: "invalid id token",
+		},
+	}
+
+	p := m.provider()
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			userInfo, err := p.VerifyIDToken(context.Background(), testCase.token(), "nonce-value")
+
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, userInfo)
+			} else {
+				require.NoError(t, err)
+				assert.Equal(t, "user-1", userInfo.Subject)
+			}
+		})
+	}
+}
+
+func TestRegistry(t *testing.T) {
+	registry := NewRegistry(
+		New(Config{Name: "keycloak"}),
+		New(Config{Name: "google"}),
+	)
+
+	p, ok := registry.Get("google")
+	assert.True(t, ok)
+	assert.Equal(t, "google", p.Name())
+
+	_, ok = registry.Get("github")
+	assert.False(t, ok)
+
+	assert.Equal(t, []string{"google", "keycloak"}, registry.Names())
+}
@@ -0,0 +1,35 @@
+package provider
+
+import "sort"
+
+type Registry struct {
+	providers map[string]*Provider
+}
+
+func NewRegistry(providers ...*Provider) *Registry {
+	registry := &Registry{providers: map[string]*Provider{}}
+	for _, p := range providers {
+		registry.Register(p)
+	}
+
+	return registry
+}
+
+func (r *Registry) Register(p *Provider) {
+	r.providers[p.Name()] = p
+}
+
+func (r *Registry) Get(name string) (*Provider, bool) {
+	p, ok := r.providers[name]
+	return p, ok
+}
+
+func (r *Registry) Names() []string {
+	names := make([]string, 0, len(r.providers))
+	for name := range r.providers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	return names
+}
@@ -2,51 +2,634 @@ package repository
 
 import (
 	"context"
+	"errors"
 	"noto/internal/services/auth/model"
+	"noto/internal/utils"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
 	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type AuthRepository interface {
-	FindOrCreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	FindUserByIdentity(ctx context.Context, identity model.Identity) (*model.User, error)
+	AddIdentity(ctx context.Context, userId primitive.ObjectID, identity model.Identity) error
+	FindUserByEmail(ctx context.Context, email string) (*model.User, error)
+	FindUserByID(ctx context.Context, userId primitive.ObjectID) (*model.User, error)
+	CreateUser(ctx context.Context, user *model.User) (*model.User, error)
+	UpdatePassword(ctx context.Context, userId primitive.ObjectID, password string) error
+	SetTOTPPendingSecret(ctx context.Context, userId primitive.ObjectID, secret string) error
+	EnableTOTP(ctx context.Context, userId primitive.ObjectID, secret string, step int64, recoveryCodes []string) error
+	DisableTOTP(ctx context.Context, userId primitive.ObjectID) error
+	SetRecoveryCodes(ctx context.Context, userId primitive.ObjectID, recoveryCodes []string) error
+	UseTOTPStep(ctx context.Context, userId primitive.ObjectID, step int64) (bool, error)
+	UseRecoveryCode(ctx context.Context, userId primitive.ObjectID, recoveryCode string) (bool, error)
+	CreateSession(ctx context.Context, session *model.Session) (*model.Session, error)
+	FindSessionByID(ctx context.Context, userId primitive.ObjectID, sessionId primitive.ObjectID) (*model.Session, error)
+	FindSessionByRefreshToken(ctx context.Context, tokenHash string) (*model.Session, error)
+	RotateSession(ctx context.Context, session *model.Session, tokenHash string, expiresAt time.Time) error
+	RevokeSession(ctx context.Context, userId primitive.ObjectID, sessionId primitive.ObjectID) error
+	RevokeUserSessions(ctx context.Context, userId primitive.ObjectID, except primitive.ObjectID, revokeUntil time.Time) error
+	RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error
+	UseToken(ctx context.Context, tokenId string, expiresAt time.Time) (bool, error)
+	RecordAttempt(ctx context.Context, tokenId string, expiresAt time.Time) (int, error)
+	IsTokenRevoked(ctx context.Context, tokenIds ...string) (bool, error)
+	EnsureIndexes(ctx context.Context) error
 }
 
+// ownedCollections holds every collection whose documents belong to a user
+// through a userId field, which move over when duplicate accounts are merged.
+var ownedCollections = []string{"books", "notes", "note_revisions", "labels", "book_labels", "note_labels", "personal_tokens"}
+
 type AuthRepositoryImpl struct {
-	users *mongo.Collection
+	db            *mongo.Database
+	users         *mongo.Collection
+	sessions      *mongo.Collection
+	revokedTokens *mongo.Collection
+	attempts      *mongo.Collection
 }
 
 func NewAuthRepository(db *mongo.Database) AuthRepository {
-	return &AuthRepositoryImpl{users: db.Collection("users")}
+	return &AuthRepositoryImpl{
+		db:            db,
+		users:         db.Collection("users"),
+		sessions:      db.Collection("sessions"),
+		revokedTokens: db.Collection("revoked_tokens"),
+		attempts:      db.Collection("token_attempts"),
+	}
+}
+
+// EnsureIndexes keeps emails and provider identities unique across accounts.
+// Accounts sharing an email, which concurrent signups could create before the
+// index existed, are merged first since the index cannot be built otherwise.
+func (r *AuthRepositoryImpl) EnsureIndexes(ctx context.Context) error {
+	if err := r.mergeDuplicateUsers(ctx); err != nil {
+		return err
+	}
+
+	_, err := r.users.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "email", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{
+			Keys: bson.D{{Key: "identities.provider", Value: 1}, {Key: "identities.subject", Value: 1}},
+			Options: options.Index().SetUnique(true).
+				SetPartialFilterExpression(bson.M{"identities": bson.M{"$exists": true}}),
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.sessions.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "refreshTokenHash", Value: 1}}},
+		{Keys: bson.D{{Key: "previousRefreshTokenHash", Value: 1}}},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+		{Keys: bson.D{{Key: "expiresAt", Value: 1}}, Options: options.Index().SetExpireAfterSeconds(0)},
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.revokedTokens.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.attempts.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+
+	return err
 }
 
-func (r *AuthRepositoryImpl) FindOrCreateUser(ctx context.Context, user *model.User) (*model.User, error) {
-	now := time.Now()
-	filter := bson.M{"email": user.Email}
+// mergeDuplicateUsers folds every account sharing an email into the oldest
+// one, each in its own transaction.
+func (r *AuthRepositoryImpl) mergeDuplicateUsers(ctx context.Context) error {
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   "$email",
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := r.users.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+
+	var groups []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return err
+	}
+
+	for _, group := range groups {
+		keep := group.IDs[0]
+		for _, extra := range group.IDs[1:] {
+			err := utils.WithTransaction(ctx, r.db, func(ctx mongo.SessionContext) error {
+				return r.mergeUser(ctx, keep, extra)
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// mergeUser moves the identities and data of extra over to keep and deletes
+// extra together with its sessions. Labels keep already has are merged into
+// its own so label names stay unique.
+func (r *AuthRepositoryImpl) mergeUser(ctx mongo.SessionContext, keep primitive.ObjectID, extra primitive.ObjectID) error {
+	var user model.User
+	if err := r.users.FindOneAndDelete(ctx, bson.M{"_id": extra}).Decode(&user); err != nil {
+		return err
+	}
+
+	if len(user.Identities) > 0 {
+		update := bson.M{"$addToSet": bson.M{"identities": bson.M{"$each": user.Identities}}}
+		if _, err := r.users.UpdateOne(ctx, bson.M{"_id": keep}, update); err != nil {
+			return err
+		}
+	}
+
+	labels := r.db.Collection("labels")
+	cursor, err := labels.Find(ctx, bson.M{"userId": extra})
+	if err != nil {
+		return err
+	}
+
+	var extraLabels []struct {
+		ID   primitive.ObjectID `bson:"_id"`
+		Name string             `bson:"name"`
+	}
+	if err := cursor.All(ctx, &extraLabels); err != nil {
+		return err
+	}
+
+	for _, label := range extraLabels {
+		var kept struct {
+			ID primitive.ObjectID `bson:"_id"`
+		}
+		err := labels.FindOne(ctx, bson.M{"userId": keep, "name": label.Name}).Decode(&kept)
+		if err == mongo.ErrNoDocuments {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+
+		for _, links := range []string{"book_labels", "note_labels"} {
+			_, err := r.db.Collection(links).UpdateMany(ctx, bson.M{"labelId": label.ID}, bson.M{"$set": bson.M{"labelId": kept.ID}})
+			if err != nil {
+				return err
+			}
+		}
+
+		if _, err := labels.DeleteOne(ctx, bson.M{"_id": label.ID}); err != nil {
+			return err
+		}
+	}
+
+	for _, name := range ownedCollections {
+		_, err := r.db.Collection(name).UpdateMany(ctx, bson.M{"userId": extra}, bson.M{"$set": bson.M{"userId": keep}})
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = r.sessions.DeleteMany(ctx, bson.M{"userId": extra})
+	return err
+}
+
+func (r *AuthRepositoryImpl) FindUserByIdentity(ctx context.Context, identity model.Identity) (*model.User, error) {
+	filter := bson.M{
+		"identities": bson.M{"$elemMatch": bson.M{
+			"provider": identity.Provider,
+			"subject":  identity.Subject,
+		}},
+	}
+
+	var user model.User
+	err := r.users.FindOne(ctx, filter).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *AuthRepositoryImpl) AddIdentity(ctx context.Context, userId primitive.ObjectID, identity model.Identity) error {
+	update := bson.M{
+		"$addToSet": bson.M{"identities": identity},
+		"$set":      bson.M{"updatedAt": time.Now()},
+	}
+
+	matched, err := r.updateUser(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("identity already linked to another account")
+		}
+		return err
+	}
+
+	if !matched {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) FindUserByEmail(ctx context.Context, email string) (*model.User, error) {
+	var user model.User
+	err := r.users.FindOne(ctx, bson.M{"email": email}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+func (r *AuthRepositoryImpl) FindUserByID(ctx context.Context, userId primitive.ObjectID) (*model.User, error) {
+	var user model.User
+	err := r.users.FindOne(ctx, bson.M{"_id": userId}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return &user, nil
+}
+
+// CreateUser relies on the unique email index, so concurrent signups for the
+// same email cannot both succeed.
+func (r *AuthRepositoryImpl) CreateUser(ctx context.Context, user *model.User) (*model.User, error) {
+	user.CreatedAt = time.Now()
+	user.UpdatedAt = time.Now()
+
+	newUser, err := r.users.InsertOne(ctx, user)
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, errors.New("email already registered")
+		}
+		return nil, err
+	}
+
+	user.ID = newUser.InsertedID.(primitive.ObjectID).Hex()
+
+	return user, nil
+}
+
+func (r *AuthRepositoryImpl) UpdatePassword(ctx context.Context, userId primitive.ObjectID, password string) error {
+	filter := bson.M{"_id": userId}
 	update := bson.M{
 		"$set": bson.M{
-			"name":      user.Name,
-			"photoUrl":  user.PhotoURL,
-			"updatedAt": now,
+			"password":  password,
+			"updatedAt": time.Now(),
 		},
-		"$setOnInsert": bson.M{
-			"createdAt": now,
+	}
+
+	updated, err := r.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if updated.MatchedCount == 0 {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) updateUser(ctx context.Context, filter bson.M, update bson.M) (bool, error) {
+	updated, err := r.users.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return updated.MatchedCount > 0, nil
+}
+
+func (r *AuthRepositoryImpl) SetTOTPPendingSecret(ctx context.Context, userId primitive.ObjectID, secret string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"totpPendingSecret": secret,
+			"updatedAt":         time.Now(),
+		},
+	}
+
+	matched, err := r.updateUser(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) EnableTOTP(ctx context.Context, userId primitive.ObjectID, secret string, step int64, recoveryCodes []string) error {
+	filter := bson.M{"_id": userId, "totpPendingSecret": secret}
+	update := bson.M{
+		"$set": bson.M{
+			"totpEnabled":   true,
+			"totpSecret":    secret,
+			"totpLastStep":  step,
+			"recoveryCodes": recoveryCodes,
+			"updatedAt":     time.Now(),
+		},
+		"$unset": bson.M{"totpPendingSecret": ""},
+	}
+
+	matched, err := r.updateUser(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return errors.New("two-factor enrollment not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) DisableTOTP(ctx context.Context, userId primitive.ObjectID) error {
+	update := bson.M{
+		"$set": bson.M{
+			"totpEnabled": false,
+			"updatedAt":   time.Now(),
+		},
+		"$unset": bson.M{
+			"totpSecret":        "",
+			"totpPendingSecret": "",
+			"totpLastStep":      "",
+			"recoveryCodes":     "",
+		},
+	}
+
+	matched, err := r.updateUser(ctx, bson.M{"_id": userId}, update)
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return errors.New("user not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) SetRecoveryCodes(ctx context.Context, userId primitive.ObjectID, recoveryCodes []string) error {
+	update := bson.M{
+		"$set": bson.M{
+			"recoveryCodes": recoveryCodes,
+			"updatedAt":     time.Now(),
 		},
 	}
-	opts := options.Update().SetUpsert(true)
 
-	_, err := r.users.UpdateOne(ctx, filter, update, opts)
+	matched, err := r.updateUser(ctx, bson.M{"_id": userId, "totpEnabled": true}, update)
+	if err != nil {
+		return err
+	}
+
+	if !matched {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) UseTOTPStep(ctx context.Context, userId primitive.ObjectID, step int64) (bool, error) {
+	filter := bson.M{
+		"_id": userId,
+		"$or": []bson.M{
+			{"totpLastStep": bson.M{"$exists": false}},
+			{"totpLastStep": bson.M{"$lt": step}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"totpLastStep": step}}
+
+	return r.updateUser(ctx, filter, update)
+}
+
+func (r *AuthRepositoryImpl) UseRecoveryCode(ctx context.Context, userId primitive.ObjectID, recoveryCode string) (bool, error) {
+	filter := bson.M{"_id": userId, "recoveryCodes": recoveryCode}
+	update := bson.M{"$pull": bson.M{"recoveryCodes": recoveryCode}}
+
+	return r.updateUser(ctx, filter, update)
+}
+
+func (r *AuthRepositoryImpl) CreateSession(ctx context.Context, session *model.Session) (*model.Session, error) {
+	session.CreatedAt = time.Now()
+	session.UpdatedAt = time.Now()
+
+	newSession, err := r.sessions.InsertOne(ctx, session)
 	if err != nil {
 		return nil, err
 	}
 
-	var currentUser model.User
-	err = r.users.FindOne(ctx, filter).Decode(&currentUser)
+	session.ID = newSession.InsertedID.(primitive.ObjectID)
+
+	return session, nil
+}
+
+func (r *AuthRepositoryImpl) FindSessionByID(ctx context.Context, userId primitive.ObjectID, sessionId primitive.ObjectID) (*model.Session, error) {
+	var session model.Session
+	err := r.sessions.FindOne(ctx, bson.M{"_id": sessionId, "userId": userId}).Decode(&session)
 	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("session not found")
+		}
 		return nil, err
 	}
 
-	return &currentUser, err
+	return &session, nil
+}
+
+func (r *AuthRepositoryImpl) FindSessionByRefreshToken(ctx context.Context, tokenHash string) (*model.Session, error) {
+	filter := bson.M{
+		"$or": []bson.M{
+			{"refreshTokenHash": tokenHash},
+			{"previousRefreshTokenHash": tokenHash},
+		},
+	}
+
+	var session model.Session
+	err := r.sessions.FindOne(ctx, filter).Decode(&session)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("session not found")
+		}
+		return nil, err
+	}
+
+	return &session, nil
+}
+
+func (r *AuthRepositoryImpl) RotateSession(ctx context.Context, session *model.Session, tokenHash string, expiresAt time.Time) error {
+	filter := bson.M{
+		"_id":              session.ID,
+		"refreshTokenHash": session.RefreshTokenHash,
+		"revokedAt":        bson.M{"$exists": false},
+	}
+	update := bson.M{
+		"$set": bson.M{
+			"refreshTokenHash":         tokenHash,
+			"previousRefreshTokenHash": session.RefreshTokenHash,
+			"expiresAt":                expiresAt,
+			"updatedAt":                time.Now(),
+		},
+	}
+
+	updated, err := r.sessions.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return err
+	}
+
+	if updated.MatchedCount == 0 {
+		return errors.New("session not found")
+	}
+
+	return nil
+}
+
+func (r *AuthRepositoryImpl) RevokeSession(ctx context.Context, userId primitive.ObjectID, sessionId primitive.ObjectID) error {
+	filter := bson.M{"_id": sessionId, "userId": userId}
+	update := bson.M{
+		"$set": bson.M{
+			"revokedAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	_, err := r.sessions.UpdateOne(ctx, filter, update)
+	return err
+}
+
+// RevokeUserSessions revokes every live session of the user except the one
+// passed as except, which may be primitive.NilObjectID to revoke them all.
+func (r *AuthRepositoryImpl) RevokeUserSessions(ctx context.Context, userId primitive.ObjectID, except primitive.ObjectID, revokeUntil time.Time) error {
+	filter := bson.M{"userId": userId, "revokedAt": bson.M{"$exists": false}}
+	if !except.IsZero() {
+		filter["_id"] = bson.M{"$ne": except}
+	}
+
+	cursor, err := r.sessions.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return err
+	}
+
+	var sessions []model.Session
+	if err := cursor.All(ctx, &sessions); err != nil {
+		return err
+	}
+
+	for _, session := range sessions {
+		if err := r.RevokeToken(ctx, session.ID.Hex(), revokeUntil); err != nil {
+			return err
+		}
+	}
+
+	update := bson.M{
+		"$set": bson.M{
+			"revokedAt": time.Now(),
+			"updatedAt": time.Now(),
+		},
+	}
+
+	_, err = r.sessions.UpdateMany(ctx, filter, update)
+	return err
+}
+
+func (r *AuthRepositoryImpl) RevokeToken(ctx context.Context, tokenId string, expiresAt time.Time) error {
+	filter := bson.M{"_id": tokenId}
+	update := bson.M{
+		"$set": bson.M{
+			"expiresAt": expiresAt,
+		},
+		"$setOnInsert": bson.M{
+			"revokedAt": time.Now(),
+		},
+	}
+
+	_, err := r.revokedTokens.UpdateOne(ctx, filter, update, options.Update().SetUpsert(true))
+	return err
+}
+
+// UseToken revokes a single-use token and reports whether this call was the
+// one that did, so a token can only be redeemed once even under concurrency.
+func (r *AuthRepositoryImpl) UseToken(ctx context.Context, tokenId string, expiresAt time.Time) (bool, error) {
+	_, err := r.revokedTokens.InsertOne(ctx, bson.M{
+		"_id":       tokenId,
+		"expiresAt": expiresAt,
+		"revokedAt": time.Now(),
+	})
+	if err != nil {
+		if mongo.IsDuplicateKeyError(err) {
+			return false, nil
+		}
+		return false, err
+	}
+
+	return true, nil
+}
+
+// RecordFailedAttempt counts a failed attempt against a token and returns the
+// number of failures so far.
+func (r *AuthRepositoryImpl) RecordAttempt(ctx context.Context, tokenId string, expiresAt time.Time) (int, error) {
+	filter := bson.M{"_id": tokenId}
+	update := bson.M{
+		"$inc":         bson.M{"count": 1},
+		"$setOnInsert": bson.M{"expiresAt": expiresAt},
+	}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+
+	var attempt struct {
+		Count int `bson:"count"`
+	}
+	if err := r.attempts.FindOneAndUpdate(ctx, filter, update, opts).Decode(&attempt); err != nil {
+		return 0, err
+	}
+
+	return attempt.Count, nil
+}
+
+func (r *AuthRepositoryImpl) IsTokenRevoked(ctx context.Context, tokenIds ...string) (bool, error) {
+	ids := []string{}
+	for _, id := range tokenIds {
+		if id != "" {
+			ids = append(ids, id)
+		}
+	}
+
+	if len(ids) == 0 {
+		return false, nil
+	}
+
+	count, err := r.revokedTokens.CountDocuments(ctx, bson.M{"_id": bson.M{"$in": ids}}, options.Count().SetLimit(1))
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
 }
@@ -1,30 +1,52 @@
 package auth
 
 import (
+	"context"
+	"log"
 	"noto/internal/config"
+	"noto/internal/middleware"
 	"noto/internal/services/auth/handler"
+	"noto/internal/services/auth/provider"
 	"noto/internal/services/auth/repository"
 	"noto/internal/services/auth/service"
+	"noto/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
-	"golang.org/x/oauth2"
-	"golang.org/x/oauth2/google"
 )
 
 func AuthRouter(router fiber.Router) {
-	googleOauthConfig := &oauth2.Config{
-		RedirectURL:  "http://localhost:8080/auth/google/callback",
-		ClientID:     config.GoogleClientID,
-		ClientSecret: config.GoogleClientSecret,
-
-		Scopes:   []string{"https://www.googleapis.com/auth/userinfo.email", "https://www.googleapis.com/auth/userinfo.profile"},
-		Endpoint: google.Endpoint,
+	providers := provider.NewRegistry()
+	for _, p := range config.OIDCProviders {
+		providers.Register(provider.New(provider.Config{
+			Name:         p.Name,
+			IssuerURL:    p.IssuerURL,
+			ClientID:     p.ClientID,
+			ClientSecret: p.ClientSecret,
+			Scopes:       p.Scopes,
+			RedirectURL:  config.OAuthRedirectBaseURL + "/auth/" + p.Name + "/callback",
+		}))
 	}
+	log.Println("OIDC providers:", providers.Names())
 
 	authRepo := repository.NewAuthRepository(config.DB)
-	authService := service.NewAuthService(authRepo, googleOauthConfig, config.JWTSecret)
+	if err := authRepo.EnsureIndexes(context.Background()); err != nil {
+		log.Println("Failed to create auth indexes:", err)
+	}
+
+	authService := service.NewAuthService(authRepo, providers, config.JWTSecret, config.AccessTokenTTL, config.RefreshTokenTTL)
 	authHandler := handler.NewAuthHandler(authService)
 
-	router.Get("/auth/google", authHandler.HandleGoogleLogin)
-	router.Get("/auth/google/callback", authHandler.HandleGoogleCallback)
+	router.Post("/auth/register", authHandler.Register)
+	router.Post("/auth/login", authHandler.Login)
+	router.Post("/auth/link", authHandler.LinkAccount)
+	router.Put("/auth/password", middleware.Protected, middleware.RequireScope(utils.ScopeAccountWrite), authHandler.ChangePassword)
+	router.Post("/auth/mfa", authHandler.VerifyMFA)
+	router.Post("/auth/totp/enroll", middleware.Protected, middleware.RequireScope(utils.ScopeAccountWrite), authHandler.EnrollTOTP)
+	router.Post("/auth/totp/verify", middleware.Protected, middleware.RequireScope(utils.ScopeAccountWrite), authHandler.EnableTOTP)
+	router.Post("/auth/totp/recovery-codes", middleware.Protected, middleware.RequireScope(utils.ScopeAccountWrite), authHandler.RegenerateRecoveryCodes)
+	router.Post("/auth/totp/disable", middleware.Protected, middleware.RequireScope(utils.ScopeAccountWrite), authHandler.DisableTOTP)
+	router.Post("/auth/refresh", authHandler.Refresh)
+	router.Post("/auth/logout", middleware.Protected, authHandler.Logout)
+	router.Get("/auth/:provider", authHandler.HandleLogin)
+	router.Get("/auth/:provider/callback", authHandler.HandleCallback)
 }
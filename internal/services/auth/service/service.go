@@ -2,92 +2,857 @@ package service
 
 import (
 	"context"
-	"encoding/json"
-	"net/http"
+	"crypto/subtle"
+	"encoding/base64"
+	"errors"
+	"net/mail"
+	"noto/internal/pkg/totp"
 	"noto/internal/services/auth/model"
+	"noto/internal/services/auth/provider"
 	"noto/internal/services/auth/repository"
+	"noto/internal/utils"
+	"strings"
 	"time"
 
 	"github.com/golang-jwt/jwt/v4"
+	"github.com/skip2/go-qrcode"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 	"golang.org/x/oauth2"
 )
 
+const (
+	OAuthStateTTL = 10 * time.Minute
+	MFATokenTTL   = 5 * time.Minute
+	LinkTokenTTL  = 10 * time.Minute
+	TOTPIssuer    = "Noto"
+
+	// MaxMFAAttempts is how many codes an mfa token accepts before it is
+	// revoked and the user has to sign in again.
+	MaxMFAAttempts = 5
+
+	// ReauthWindow is how recently an account without a password must have
+	// signed in before it can set one.
+	ReauthWindow = 10 * time.Minute
+
+	recoveryCodeCount = 10
+)
+
 type AuthService interface {
-	HandleGoogleLogin() string
-	HandleGoogleCallback(code string) (*model.AuthToken, error)
+	HandleLogin(providerName string) (*model.OAuthLogin, error)
+	HandleCallback(providerName string, code string, state string, stateToken string) (*model.AuthToken, error)
+	Register(register *model.Register) (*model.AuthToken, error)
+	Login(login *model.Login) (*model.AuthToken, error)
+	LinkAccount(link *model.LinkAccount) (*model.AuthToken, error)
+	ChangePassword(claims *model.TokenClaims, password *model.ChangePassword) error
+	Refresh(refreshToken string) (*model.AuthToken, error)
+	Logout(claims *model.TokenClaims, all bool) error
+	VerifyMFA(verify *model.MFAVerify) (*model.AuthToken, error)
+	EnrollTOTP(userId primitive.ObjectID) (*model.TOTPEnrollment, error)
+	EnableTOTP(userId primitive.ObjectID, code string) (*model.RecoveryCodes, error)
+	RegenerateRecoveryCodes(userId primitive.ObjectID, code string) (*model.RecoveryCodes, error)
+	DisableTOTP(userId primitive.ObjectID, disable *model.TOTPDisable) error
 }
 
 type authService struct {
-	authRepo          repository.AuthRepository
-	googleOauthConfig *oauth2.Config
-	jwtSecret         []byte
+	authRepo        repository.AuthRepository
+	providers       *provider.Registry
+	jwtSecret       []byte
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
 }
 
-func NewAuthService(authRepo repository.AuthRepository, googleOauthConfig *oauth2.Config, jwtSecret []byte) AuthService {
+func NewAuthService(authRepo repository.AuthRepository, providers *provider.Registry, jwtSecret []byte, accessTokenTTL time.Duration, refreshTokenTTL time.Duration) AuthService {
 	return &authService{
-		authRepo:          authRepo,
-		googleOauthConfig: googleOauthConfig,
-		jwtSecret:         jwtSecret,
+		authRepo:        authRepo,
+		providers:       providers,
+		jwtSecret:       jwtSecret,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
 	}
 }
 
-func (s *authService) HandleGoogleLogin() string {
-	return s.googleOauthConfig.AuthCodeURL("state")
+func (s *authService) HandleLogin(providerName string) (*model.OAuthLogin, error) {
+	p, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, errors.New("provider not found")
+	}
+
+	state, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	nonce, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	verifier := oauth2.GenerateVerifier()
+	stateToken, err := s.createStateToken(&oauthState{
+		Provider: providerName,
+		State:    state,
+		Nonce:    nonce,
+		Verifier: verifier,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	url, err := p.AuthCodeURL(context.Background(), state, nonce, verifier)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.OAuthLogin{
+		URL:        url,
+		StateToken: stateToken,
+	}, nil
 }
 
-func (s *authService) HandleGoogleCallback(code string) (*model.AuthToken, error) {
-	token, err := s.googleOauthConfig.Exchange(context.Background(), code)
+func (s *authService) HandleCallback(providerName string, code string, state string, stateToken string) (*model.AuthToken, error) {
+	p, ok := s.providers.Get(providerName)
+	if !ok {
+		return nil, errors.New("provider not found")
+	}
+
+	oauth, err := s.verifyStateToken(providerName, state, stateToken)
 	if err != nil {
 		return nil, err
 	}
 
-	userInfo, err := s.getUserInfo(token.AccessToken)
+	if code == "" {
+		return nil, errors.New("missing authorization code")
+	}
+
+	userInfo, err := p.Exchange(context.Background(), code, oauth.Verifier, oauth.Nonce)
+	if err != nil {
+		return nil, err
+	}
+
+	if userInfo.Subject == "" {
+		return nil, errors.New("provider did not return a subject")
+	}
+
+	if userInfo.Email == "" {
+		return nil, errors.New("provider did not return an email address")
+	}
+
+	if userInfo.EmailVerified == nil || !*userInfo.EmailVerified {
+		return nil, errors.New("email address is not verified")
+	}
+
+	identity := model.Identity{Provider: providerName, Subject: userInfo.Subject}
+	user, err := s.authRepo.FindUserByIdentity(context.Background(), identity)
+	if err == nil {
+		return s.completeLogin(user)
+	}
+	if err.Error() != "user not found" {
+		return nil, err
+	}
+
+	email := strings.ToLower(userInfo.Email)
+	user, err = s.authRepo.FindUserByEmail(context.Background(), email)
+	if err != nil {
+		if err.Error() != "user not found" {
+			return nil, err
+		}
+
+		name := userInfo.Name
+		if name == "" {
+			name = userInfo.Email
+		}
+
+		newUser, err := s.authRepo.CreateUser(context.Background(), &model.User{
+			Email:      email,
+			Name:       name,
+			PhotoURL:   userInfo.Picture,
+			Identities: []model.Identity{identity},
+		})
+		if err != nil {
+			// A concurrent first login with the same identity created the
+			// account in the meantime.
+			if err.Error() == "email already registered" {
+				if user, err := s.authRepo.FindUserByIdentity(context.Background(), identity); err == nil {
+					return s.completeLogin(user)
+				}
+			}
+			return nil, err
+		}
+
+		return s.completeLogin(newUser)
+	}
+
+	// An account with a password belongs to whoever knows it, which is not
+	// necessarily the owner of the provider account, so linking has to be
+	// confirmed with that password through /auth/link.
+	if user.Password != "" {
+		linkToken, err := s.createLinkToken(user, identity)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.AuthToken{
+			LinkRequired: true,
+			LinkToken:    linkToken,
+		}, nil
+	}
+
+	userId, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return nil, errors.New("invalid user id format")
+	}
+
+	if err := s.authRepo.AddIdentity(context.Background(), userId, identity); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(user)
+}
+
+func (s *authService) LinkAccount(link *model.LinkAccount) (*model.AuthToken, error) {
+	userId, identity, err := s.verifyLinkToken(link.LinkToken)
+	if err != nil {
+		return nil, err
+	}
+
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid link token")
+		}
+		return nil, err
+	}
+
+	if !utils.CheckPassword(user.Password, link.Password) {
+		return nil, errors.New("current password is incorrect")
+	}
+
+	if err := s.authRepo.AddIdentity(context.Background(), userId, *identity); err != nil {
+		return nil, err
+	}
+
+	return s.completeLogin(user)
+}
+
+func (s *authService) Register(register *model.Register) (*model.AuthToken, error) {
+	email := strings.ToLower(strings.TrimSpace(register.Email))
+	if _, err := mail.ParseAddress(email); err != nil {
+		return nil, errors.New("invalid email address")
+	}
+
+	name := strings.TrimSpace(register.Name)
+	if name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if err := utils.ValidatePassword(register.Password); err != nil {
+		return nil, err
+	}
+
+	hash, err := utils.HashPassword(register.Password)
 	if err != nil {
 		return nil, err
 	}
 
 	user := &model.User{
-		ID:       userInfo["id"].(string),
-		Email:    userInfo["email"].(string),
-		Name:     userInfo["name"].(string),
-		PhotoURL: userInfo["picture"].(string),
+		Email:    email,
+		Name:     name,
+		Password: hash,
 	}
 
-	currentUser, err := s.authRepo.FindOrCreateUser(context.Background(), user)
+	newUser, err := s.authRepo.CreateUser(context.Background(), user)
 	if err != nil {
 		return nil, err
 	}
 
-	jwtToken, err := s.createJWTToken(currentUser)
+	return s.issueToken(newUser)
+}
+
+func (s *authService) Login(login *model.Login) (*model.AuthToken, error) {
+	email := strings.ToLower(strings.TrimSpace(login.Email))
+
+	user, err := s.authRepo.FindUserByEmail(context.Background(), email)
 	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid email or password")
+		}
 		return nil, err
 	}
 
-	return &model.AuthToken{Token: jwtToken}, nil
+	if !utils.CheckPassword(user.Password, login.Password) {
+		return nil, errors.New("invalid email or password")
+	}
+
+	return s.completeLogin(user)
+}
+
+// completeLogin issues a token once the first factor has been checked, or a
+// challenge to complete at /auth/mfa for accounts with two-factor
+// authentication.
+func (s *authService) completeLogin(user *model.User) (*model.AuthToken, error) {
+	if user.TOTPEnabled {
+		mfaToken, err := s.createMFAToken(user)
+		if err != nil {
+			return nil, err
+		}
+
+		return &model.AuthToken{
+			MFARequired: true,
+			MFAToken:    mfaToken,
+		}, nil
+	}
+
+	return s.issueToken(user)
+}
+
+// VerifyMFA redeems an mfa token once. Every attempt is counted against the
+// token before the code is checked, so parallel guesses cannot get past
+// MaxMFAAttempts, and the token is revoked once the limit is reached.
+func (s *authService) VerifyMFA(verify *model.MFAVerify) (*model.AuthToken, error) {
+	userId, tokenId, expiresAt, err := s.verifyMFAToken(verify.MFAToken)
+	if err != nil {
+		return nil, err
+	}
+
+	revoked, err := s.authRepo.IsTokenRevoked(context.Background(), tokenId)
+	if err != nil {
+		return nil, err
+	}
+	if revoked {
+		return nil, errors.New("invalid mfa token")
+	}
+
+	attempts, err := s.authRepo.RecordAttempt(context.Background(), tokenId, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if attempts > MaxMFAAttempts {
+		if err := s.authRepo.RevokeToken(context.Background(), tokenId, expiresAt); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("too many two-factor attempts")
+	}
+
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return nil, errors.New("invalid mfa token")
+		}
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("invalid mfa token")
+	}
+
+	if err := s.verifySecondFactor(userId, user, verify.Code, verify.RecoveryCode); err != nil {
+		return nil, err
+	}
+
+	// Revoking the token is the step that redeems it, so of two correct codes
+	// sent at once only one signs in.
+	used, err := s.authRepo.UseToken(context.Background(), tokenId, expiresAt)
+	if err != nil {
+		return nil, err
+	}
+	if !used {
+		return nil, errors.New("invalid mfa token")
+	}
+
+	return s.issueToken(user)
+}
+
+func (s *authService) EnrollTOTP(userId primitive.ObjectID) (*model.TOTPEnrollment, error) {
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if user.Password == "" {
+		return nil, errors.New("two-factor authentication requires a password")
+	}
+
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	secret, err := totp.GenerateSecret()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.SetTOTPPendingSecret(context.Background(), userId, secret); err != nil {
+		return nil, err
+	}
+
+	uri := totp.URI(TOTPIssuer, user.Email, secret)
+	png, err := qrcode.Encode(uri, qrcode.Medium, 256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TOTPEnrollment{
+		Secret: secret,
+		URI:    uri,
+		QRCode: "data:image/png;base64," + base64.StdEncoding.EncodeToString(png),
+	}, nil
 }
 
-func (s *authService) getUserInfo(accessToken string) (map[string]interface{}, error) {
-	resp, err := http.Get("https://www.googleapis.com/oauth2/v2/userinfo?access_token=" + accessToken)
+func (s *authService) EnableTOTP(userId primitive.ObjectID, code string) (*model.RecoveryCodes, error) {
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
 	if err != nil {
 		return nil, err
 	}
-	defer resp.Body.Close()
 
-	var userInfo map[string]interface{}
-	err = json.NewDecoder(resp.Body).Decode(&userInfo)
+	if user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is already enabled")
+	}
+
+	if user.TOTPPendingSecret == "" {
+		return nil, errors.New("two-factor enrollment not found")
+	}
+
+	step, ok := totp.Validate(user.TOTPPendingSecret, code, time.Now())
+	if !ok {
+		return nil, errors.New("invalid two-factor code")
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
 	if err != nil {
 		return nil, err
 	}
-	return userInfo, err
+
+	if err := s.authRepo.EnableTOTP(context.Background(), userId, user.TOTPPendingSecret, step, hashes); err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodes{RecoveryCodes: codes}, nil
 }
 
-func (s *authService) createJWTToken(user *model.User) (string, error) {
+func (s *authService) RegenerateRecoveryCodes(userId primitive.ObjectID, code string) (*model.RecoveryCodes, error) {
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
+	if err != nil {
+		return nil, err
+	}
+
+	if !user.TOTPEnabled {
+		return nil, errors.New("two-factor authentication is not enabled")
+	}
+
+	if err := s.verifySecondFactor(userId, user, code, ""); err != nil {
+		return nil, err
+	}
+
+	codes, hashes, err := generateRecoveryCodes()
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.authRepo.SetRecoveryCodes(context.Background(), userId, hashes); err != nil {
+		return nil, err
+	}
+
+	return &model.RecoveryCodes{RecoveryCodes: codes}, nil
+}
+
+func (s *authService) DisableTOTP(userId primitive.ObjectID, disable *model.TOTPDisable) error {
+	user, err := s.authRepo.FindUserByID(context.Background(), userId)
+	if err != nil {
+		return err
+	}
+
+	if !user.TOTPEnabled {
+		return errors.New("two-factor authentication is not enabled")
+	}
+
+	if !utils.CheckPassword(user.Password, disable.Password) {
+		return errors.New("current password is incorrect")
+	}
+
+	if err := s.verifySecondFactor(userId, user, disable.Code, disable.RecoveryCode); err != nil {
+		return err
+	}
+
+	return s.authRepo.DisableTOTP(context.Background(), userId)
+}
+
+// verifySecondFactor accepts either a TOTP code or a recovery code. Both are
+// consumed atomically so a code cannot be replayed within its time window.
+func (s *authService) verifySecondFactor(userId primitive.ObjectID, user *model.User, code string, recoveryCode string) error {
+	if code != "" {
+		step, ok := totp.Validate(user.TOTPSecret, code, time.Now())
+		if !ok {
+			return errors.New("invalid two-factor code")
+		}
+
+		used, err := s.authRepo.UseTOTPStep(context.Background(), userId, step)
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errors.New("invalid two-factor code")
+		}
+
+		return nil
+	}
+
+	if recoveryCode != "" {
+		used, err := s.authRepo.UseRecoveryCode(context.Background(), userId, utils.HashToken(normalizeRecoveryCode(recoveryCode)))
+		if err != nil {
+			return err
+		}
+		if !used {
+			return errors.New("invalid two-factor code")
+		}
+
+		return nil
+	}
+
+	return errors.New("two-factor code is required")
+}
+
+func generateRecoveryCodes() ([]string, []string, error) {
+	codes := make([]string, 0, recoveryCodeCount)
+	hashes := make([]string, 0, recoveryCodeCount)
+	for i := 0; i < recoveryCodeCount; i++ {
+		secret, err := totp.GenerateSecret()
+		if err != nil {
+			return nil, nil, err
+		}
+
+		code := strings.ToLower(secret[:5] + "-" + secret[5:10])
+		codes = append(codes, code)
+		hashes = append(hashes, utils.HashToken(code))
+	}
+
+	return codes, hashes, nil
+}
+
+func normalizeRecoveryCode(code string) string {
+	return strings.ToLower(strings.TrimSpace(code))
+}
+
+// ChangePassword checks the current password before replacing it. Accounts
+// created through a provider have no password yet, so setting the first one
+// requires a session that signed in within ReauthWindow instead. Every other
+// session of the user is revoked once the password has changed.
+func (s *authService) ChangePassword(claims *model.TokenClaims, password *model.ChangePassword) error {
+	user, err := s.authRepo.FindUserByID(context.Background(), claims.UserId)
+	if err != nil {
+		return err
+	}
+
+	if user.Password == "" {
+		if err := s.checkRecentSignIn(claims); err != nil {
+			return err
+		}
+	} else if !utils.CheckPassword(user.Password, password.CurrentPassword) {
+		return errors.New("current password is incorrect")
+	}
+
+	if err := utils.ValidatePassword(password.NewPassword); err != nil {
+		return err
+	}
+
+	hash, err := utils.HashPassword(password.NewPassword)
+	if err != nil {
+		return err
+	}
+
+	if err := s.authRepo.UpdatePassword(context.Background(), claims.UserId, hash); err != nil {
+		return err
+	}
+
+	// Personal access tokens have no session, in which case every session
+	// is revoked.
+	sessionId, _ := primitive.ObjectIDFromHex(claims.SessionId)
+
+	return s.authRepo.RevokeUserSessions(context.Background(), claims.UserId, sessionId, time.Now().Add(s.accessTokenTTL))
+}
+
+func (s *authService) checkRecentSignIn(claims *model.TokenClaims) error {
+	sessionId, err := primitive.ObjectIDFromHex(claims.SessionId)
+	if err != nil {
+		return errors.New("sign in again to set a password")
+	}
+
+	session, err := s.authRepo.FindSessionByID(context.Background(), claims.UserId, sessionId)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return errors.New("sign in again to set a password")
+		}
+		return err
+	}
+
+	if session.RevokedAt != nil || time.Since(session.CreatedAt) > ReauthWindow {
+		return errors.New("sign in again to set a password")
+	}
+
+	return nil
+}
+
+type oauthState struct {
+	Provider string `json:"provider"`
+	State    string `json:"state"`
+	Nonce    string `json:"nonce"`
+	Verifier string `json:"verifier"`
+	Type     string `json:"typ"`
+	jwt.RegisteredClaims
+}
+
+func (s *authService) createStateToken(state *oauthState) (string, error) {
+	state.Subject = "oauth_state"
+	state.Type = "oauth_state"
+	state.ExpiresAt = jwt.NewNumericDate(time.Now().Add(OAuthStateTTL))
+
+	return jwt.NewWithClaims(jwt.SigningMethodHS256, state).SignedString(s.jwtSecret)
+}
+
+func (s *authService) verifyStateToken(providerName string, state string, stateToken string) (*oauthState, error) {
+	if state == "" || stateToken == "" {
+		return nil, errors.New("invalid oauth state")
+	}
+
+	claims := &oauthState{}
+	token, err := jwt.ParseWithClaims(stateToken, claims, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil || !token.Valid || claims.Type != "oauth_state" {
+		return nil, errors.New("invalid oauth state")
+	}
+
+	if claims.Provider != providerName || claims.Verifier == "" || claims.Nonce == "" {
+		return nil, errors.New("invalid oauth state")
+	}
+
+	if subtle.ConstantTimeCompare([]byte(claims.State), []byte(state)) != 1 {
+		return nil, errors.New("invalid oauth state")
+	}
+
+	return claims, nil
+}
+
+func (s *authService) createMFAToken(user *model.User) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ": "mfa",
+		"id":  user.ID,
+		"jti": primitive.NewObjectID().Hex(),
+		"exp": time.Now().Add(MFATokenTTL).Unix(),
+	})
+
+	return token.SignedString(s.jwtSecret)
+}
+
+func (s *authService) verifyMFAToken(mfaToken string) (primitive.ObjectID, string, time.Time, error) {
+	token, err := jwt.Parse(mfaToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return primitive.NilObjectID, "", time.Time{}, errors.New("invalid mfa token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "mfa" {
+		return primitive.NilObjectID, "", time.Time{}, errors.New("invalid mfa token")
+	}
+
+	id, _ := claims["id"].(string)
+	userId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, "", time.Time{}, errors.New("invalid mfa token")
+	}
+
+	tokenId, _ := claims["jti"].(string)
+	exp, _ := claims["exp"].(float64)
+	if tokenId == "" || exp == 0 {
+		return primitive.NilObjectID, "", time.Time{}, errors.New("invalid mfa token")
+	}
+
+	return userId, tokenId, time.Unix(int64(exp), 0), nil
+}
+
+func (s *authService) createLinkToken(user *model.User, identity model.Identity) (string, error) {
+	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ":      "link",
+		"id":       user.ID,
+		"provider": identity.Provider,
+		"sub":      identity.Subject,
+		"exp":      time.Now().Add(LinkTokenTTL).Unix(),
+	})
+
+	return token.SignedString(s.jwtSecret)
+}
+
+func (s *authService) verifyLinkToken(linkToken string) (primitive.ObjectID, *model.Identity, error) {
+	token, err := jwt.Parse(linkToken, func(token *jwt.Token) (interface{}, error) {
+		if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
+			return nil, errors.New("invalid signing method")
+		}
+		return s.jwtSecret, nil
+	})
+	if err != nil || !token.Valid {
+		return primitive.NilObjectID, nil, errors.New("invalid link token")
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || claims["typ"] != "link" {
+		return primitive.NilObjectID, nil, errors.New("invalid link token")
+	}
+
+	id, _ := claims["id"].(string)
+	userId, err := primitive.ObjectIDFromHex(id)
+	if err != nil {
+		return primitive.NilObjectID, nil, errors.New("invalid link token")
+	}
+
+	identity := &model.Identity{}
+	identity.Provider, _ = claims["provider"].(string)
+	identity.Subject, _ = claims["sub"].(string)
+	if identity.Provider == "" || identity.Subject == "" {
+		return primitive.NilObjectID, nil, errors.New("invalid link token")
+	}
+
+	return userId, identity, nil
+}
+
+func (s *authService) Refresh(refreshToken string) (*model.AuthToken, error) {
+	if refreshToken == "" {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	tokenHash := utils.HashToken(refreshToken)
+	session, err := s.authRepo.FindSessionByRefreshToken(context.Background(), tokenHash)
+	if err != nil {
+		if err.Error() == "session not found" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	if session.RevokedAt != nil || time.Now().After(session.ExpiresAt) {
+		return nil, errors.New("invalid refresh token")
+	}
+
+	// A rotated-out token being presented again means it was leaked, so the
+	// whole session is revoked rather than just rejecting this request.
+	if session.RefreshTokenHash != tokenHash {
+		if err := s.revokeSession(session.UserId, session.ID); err != nil {
+			return nil, err
+		}
+		return nil, errors.New("invalid refresh token")
+	}
+
+	user, err := s.authRepo.FindUserByID(context.Background(), session.UserId)
+	if err != nil {
+		return nil, err
+	}
+
+	newRefreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	err = s.authRepo.RotateSession(context.Background(), session, utils.HashToken(newRefreshToken), time.Now().Add(s.refreshTokenTTL))
+	if err != nil {
+		if err.Error() == "session not found" {
+			return nil, errors.New("invalid refresh token")
+		}
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.createJWTToken(user, session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthToken{
+		Token:        accessToken,
+		RefreshToken: newRefreshToken,
+		ExpiresAt:    expiresAt.Unix(),
+	}, nil
+}
+
+func (s *authService) Logout(claims *model.TokenClaims, all bool) error {
+	if all {
+		return s.authRepo.RevokeUserSessions(context.Background(), claims.UserId, primitive.NilObjectID, time.Now().Add(s.accessTokenTTL))
+	}
+
+	if claims.TokenId != "" {
+		if err := s.authRepo.RevokeToken(context.Background(), claims.TokenId, claims.ExpiresAt); err != nil {
+			return err
+		}
+	}
+
+	sessionId, err := primitive.ObjectIDFromHex(claims.SessionId)
+	if err != nil {
+		return nil
+	}
+
+	return s.revokeSession(claims.UserId, sessionId)
+}
+
+func (s *authService) revokeSession(userId primitive.ObjectID, sessionId primitive.ObjectID) error {
+	if err := s.authRepo.RevokeSession(context.Background(), userId, sessionId); err != nil {
+		return err
+	}
+
+	return s.authRepo.RevokeToken(context.Background(), sessionId.Hex(), time.Now().Add(s.accessTokenTTL))
+}
+
+func (s *authService) issueToken(user *model.User) (*model.AuthToken, error) {
+	userId, err := primitive.ObjectIDFromHex(user.ID)
+	if err != nil {
+		return nil, errors.New("invalid user id format")
+	}
+
+	refreshToken, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	session, err := s.authRepo.CreateSession(context.Background(), &model.Session{
+		UserId:           userId,
+		RefreshTokenHash: utils.HashToken(refreshToken),
+		ExpiresAt:        time.Now().Add(s.refreshTokenTTL),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	accessToken, expiresAt, err := s.createJWTToken(user, session.ID.Hex())
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.AuthToken{
+		Token:        accessToken,
+		RefreshToken: refreshToken,
+		ExpiresAt:    expiresAt.Unix(),
+	}, nil
+}
+
+func (s *authService) createJWTToken(user *model.User, sessionId string) (string, time.Time, error) {
+	expiresAt := time.Now().Add(s.accessTokenTTL)
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, jwt.MapClaims{
+		"typ":   "access",
 		"id":    user.ID,
 		"email": user.Email,
 		"name":  user.Name,
-		"exp":   time.Now().Add(time.Hour * 24).Unix(),
+		"jti":   primitive.NewObjectID().Hex(),
+		"sid":   sessionId,
+		"exp":   expiresAt.Unix(),
 	})
 
-	return token.SignedString(s.jwtSecret)
+	signed, err := token.SignedString(s.jwtSecret)
+	if err != nil {
+		return "", time.Time{}, err
+	}
+
+	return signed, expiresAt, nil
 }
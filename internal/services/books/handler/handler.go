@@ -7,6 +7,7 @@ import (
 	"noto/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type BookHandler interface {
@@ -15,6 +16,7 @@ type BookHandler interface {
 	GetBook(c *fiber.Ctx) error
 	UpdateBook(c *fiber.Ctx) error
 	ArchiveBook(c *fiber.Ctx) error
+	DeleteBook(c *fiber.Ctx) error
 }
 
 type BookHandlerImpl struct {
@@ -56,21 +58,34 @@ func (s *BookHandlerImpl) CreateBook(c *fiber.Ctx) error {
 		return utils.ErrorInternalServer(c, "failed to create book: "+err.Error())
 	}
 
+	utils.SetETag(c, newBook.Version)
 	return c.Status(fiber.StatusCreated).JSON(newBook)
 }
 
 // GetBooks
 // @Summary		Get all book
-// @Description	Get all book
+// @Description	Get all book. Pages are numbered by default; sending a cursor parameter (empty for the first page)
+// @Description	switches to keyset pagination, which answers with model.CursorBookResponse and a next_cursor.
 // @Tags		Books
 // @Security 	BearerAuth
 // @Produce		json
 // @Param 		Authorization header string false "Bearer token"
 // @Param		is_archived	query		bool	false	"Filter by archive status"
+// @Param		sort		query		string	false	"Sort by created_at, updated_at or title, optionally followed by :asc or :desc"	example(title:asc)
+// @Param		created_after	query	string	false	"Only books created after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		created_before	query	string	false	"Only books created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		updated_after	query	string	false	"Only books updated after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		updated_before	query	string	false	"Only books updated before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		prefix		query		string	false	"Only books whose title starts with this text, ignoring case"
+// @Param		labels		query		string	false	"Comma separated label names the books must carry"	example(work,urgent)
+// @Param		match		query		string	false	"Whether books need all of the labels or any of them"	Enums(all, any)	default(all)
+// @Param		exclude		query		string	false	"Comma separated label names the books must not carry"	example(done)
+// @Param		cursor		query		string	false	"Cursor from next_cursor for keyset pagination"
 // @Param		page		query		int		false	"Page number for pagination"	minimum(1)
 // @Param		limit		query		int		false	"Number of items per page"	minimum(1)
 // @Success		200		{object}	model.PaginatedBookResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books [get]
 func (s *BookHandlerImpl) GetBooks(c *fiber.Ctx) error {
@@ -83,7 +98,33 @@ func (s *BookHandlerImpl) GetBooks(c *fiber.Ctx) error {
 	limit := c.QueryInt("limit", 10)
 	isArchived := c.QueryBool("is_archived", false)
 
-	books, err := s.bookService.GetBooks(userId, isArchived, page, limit)
+	query, err := utils.ParseListQuery(c, model.BookSortFields)
+	if err != nil {
+		return utils.ErrorValidation(c, err.Error())
+	}
+
+	labels, err := utils.ParseLabelFilter(c)
+	if err != nil {
+		return utils.ErrorValidation(c, err.Error())
+	}
+
+	if cursor, ok := utils.CursorQuery(c); ok {
+		if limit < 1 {
+			return utils.ErrorValidation(c, "limit must be at least 1")
+		}
+
+		books, err := s.bookService.GetBooksByCursor(userId, isArchived, query, labels, cursor, limit)
+		if err != nil {
+			if err.Error() == "invalid cursor" {
+				return utils.ErrorValidation(c, err.Error())
+			}
+			return utils.ErrorInternalServer(c, err.Error())
+		}
+
+		return c.JSON(books)
+	}
+
+	books, err := s.bookService.GetBooks(userId, isArchived, query, labels, page, limit)
 	if err != nil {
 		return utils.ErrorInternalServer(c, err.Error())
 	}
@@ -100,8 +141,10 @@ func (s *BookHandlerImpl) GetBooks(c *fiber.Ctx) error {
 // @Param 		Authorization header string false "Bearer token"
 // @Param 		bookId path string true "Book ID"
 // @Success		200		{object}	model.BookResponse
+// @Header		200		{string}	ETag	"Current book version"
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId} [get]
 func (s *BookHandlerImpl) GetBook(c *fiber.Ctx) error {
@@ -122,6 +165,8 @@ func (s *BookHandlerImpl) GetBook(c *fiber.Ctx) error {
 		}
 		return utils.ErrorInternalServer(c, err.Error())
 	}
+
+	utils.SetETag(c, book.Version)
 	return c.JSON(book)
 }
 
@@ -134,10 +179,14 @@ func (s *BookHandlerImpl) GetBook(c *fiber.Ctx) error {
 // @Accept		json
 // @Param 		Authorization header string false "Bearer token"
 // @Param 		bookId path string true "Book ID"
+// @Param 		If-Match header string false "Expected book version (ETag)"
 // @Param		book	body		model.BookUpdateSwagger	true	"Book to update"
 // @Success		200		{object}	model.BookResponse
+// @Header		200		{string}	ETag	"New book version"
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ConflictResponse{current=model.BookResponse}
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId} [put]
 func (s *BookHandlerImpl) UpdateBook(c *fiber.Ctx) error {
@@ -151,6 +200,11 @@ func (s *BookHandlerImpl) UpdateBook(c *fiber.Ctx) error {
 		return utils.ErrorBadRequest(c, err.Error())
 	}
 
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
 	book := new(model.BookUpdate)
 	if err := c.BodyParser(&book); err != nil {
 		return utils.ErrorBadRequest(c, "cannot parse json: "+err.Error())
@@ -163,11 +217,13 @@ func (s *BookHandlerImpl) UpdateBook(c *fiber.Ctx) error {
 
 	book.ID = bookId
 	book.UserId = userId
+	book.Version = version
 	updatedBook, err := s.bookService.UpdateBook(book)
 	if err != nil {
-		return utils.ErrorInternalServer(c, "failed to update book: "+err.Error())
+		return s.updateError(c, userId, bookId, "failed to update book: ", err)
 	}
 
+	utils.SetETag(c, updatedBook.Version)
 	return c.JSON(updatedBook)
 }
 
@@ -180,10 +236,14 @@ func (s *BookHandlerImpl) UpdateBook(c *fiber.Ctx) error {
 // @Accept		json
 // @Param 		Authorization header string false "Bearer token"
 // @Param 		bookId path string true "Book ID"
+// @Param 		If-Match header string false "Expected book version (ETag)"
 // @Param		book	body		model.ArchiveBookSwagger true	"Book to archive"
 // @Success		200		{object}	model.BookResponse
+// @Header		200		{string}	ETag	"New book version"
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ConflictResponse{current=model.BookResponse}
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId} [patch]
 func (s *BookHandlerImpl) ArchiveBook(c *fiber.Ctx) error {
@@ -197,6 +257,11 @@ func (s *BookHandlerImpl) ArchiveBook(c *fiber.Ctx) error {
 		return utils.ErrorBadRequest(c, err.Error())
 	}
 
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
 	book := new(model.ArchiveBook)
 	if err := c.BodyParser(&book); err != nil {
 		return utils.ErrorBadRequest(c, "cannot parse json: "+err.Error())
@@ -204,10 +269,67 @@ func (s *BookHandlerImpl) ArchiveBook(c *fiber.Ctx) error {
 
 	book.ID = bookId
 	book.UserId = userId
+	book.Version = version
 	archived, err := s.bookService.ArchiveBook(book)
 	if err != nil {
-		return utils.ErrorInternalServer(c, "failed to archive book: "+err.Error())
+		return s.updateError(c, userId, bookId, "failed to archive book: ", err)
 	}
 
+	utils.SetETag(c, archived.Version)
 	return c.JSON(archived)
 }
+
+// updateError replies to a failed update, sending the current server copy
+// along with a 409 when the client's If-Match version was stale.
+func (s *BookHandlerImpl) updateError(c *fiber.Ctx, userId primitive.ObjectID, bookId primitive.ObjectID, prefix string, err error) error {
+	switch err.Error() {
+	case "book not found":
+		return utils.ErrorNotFound(c, err.Error())
+	case "version conflict":
+		current, err := s.bookService.GetBook(userId, bookId)
+		if err != nil {
+			return utils.ErrorInternalServer(c, prefix+err.Error())
+		}
+		utils.SetETag(c, current.Version)
+		return utils.ErrorConflictWithCurrent(c, "version conflict", current)
+	}
+
+	return utils.ErrorInternalServer(c, prefix+err.Error())
+}
+
+// DeleteBook
+// @Summary		Delete book by id
+// @Description	Move book by id to the trash. Its notes and labels are restored together with it.
+// @Tags		Books
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param 		bookId path string true "Book ID"
+// @Success		200		{object}	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId} [delete]
+func (s *BookHandlerImpl) DeleteBook(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.bookService.DeleteBook(userId, bookId); err != nil {
+		if err.Error() == "book not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to delete book: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "book deleted",
+	})
+}
@@ -17,6 +17,7 @@ type BookCreate struct {
 	CreatedAt  time.Time          `json:"created_at" bson:"createdAt"`
 	UpdatedAt  time.Time          `json:"updated_at" bson:"updatedAt"`
 	IsArchived bool               `json:"is_archived" bson:"isArchived"`
+	Version    int64              `json:"version" bson:"version"`
 }
 
 type BookUpdateSwagger struct {
@@ -27,20 +28,26 @@ type BookUpdate struct {
 	ID     primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	UserId primitive.ObjectID `json:"user_id" bson:"userId"`
 	Title  string             `json:"title" bson:"title"`
+	// Version is the expected current version taken from If-Match, nil to skip the check.
+	Version *int64 `json:"-" bson:"-"`
 }
 
 type Label struct {
-	ID   string `json:"id" bson:"_id"`
-	Name string `json:"name" bson:"name"`
+	ID    string `json:"id" bson:"_id"`
+	Name  string `json:"name" bson:"name"`
+	Color string `json:"color,omitempty" bson:"color,omitempty"`
+	Icon  string `json:"icon,omitempty" bson:"icon,omitempty"`
 }
 
 type BookResponse struct {
-	ID         string    `json:"id" bson:"_id"`
-	Title      string    `json:"title" bson:"title"`
-	CreatedAt  time.Time `json:"created_at" bson:"createdAt"`
-	UpdatedAt  time.Time `json:"updated_at" bson:"updatedAt"`
-	IsArchived bool      `json:"is_archived" bson:"isArchived"`
-	Labels     []Label   `json:"labels" bson:"labels"`
+	ID         string     `json:"id" bson:"_id"`
+	Title      string     `json:"title" bson:"title"`
+	CreatedAt  time.Time  `json:"created_at" bson:"createdAt"`
+	UpdatedAt  time.Time  `json:"updated_at" bson:"updatedAt"`
+	IsArchived bool       `json:"is_archived" bson:"isArchived"`
+	Labels     []Label    `json:"labels" bson:"labels"`
+	DeletedAt  *time.Time `json:"deleted_at,omitempty" bson:"deletedAt,omitempty"`
+	Version    int64      `json:"version" bson:"version"`
 }
 
 type PaginationMetadata struct {
@@ -51,11 +58,25 @@ type PaginationMetadata struct {
 	NextPage     *int `json:"nextPage" bson:"nextPage"`
 }
 
+// BookSortFields are the values accepted by the sort parameter of book lists.
+var BookSortFields = map[string]string{
+	"created_at": "createdAt",
+	"updated_at": "updatedAt",
+	"title":      "title",
+}
+
 type PaginatedBookResponse struct {
 	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
 	Data     []BookResponse     `json:"data" bson:"data"`
 }
 
+// CursorBookResponse is a page of books in keyset pagination. NextCursor is
+// null on the last page.
+type CursorBookResponse struct {
+	Data       []BookResponse `json:"data"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
 type ArchiveBookSwagger struct {
 	IsArchived bool `json:"is_archived" bson:"isArchived"`
 }
@@ -64,4 +85,6 @@ type ArchiveBook struct {
 	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	UserId     primitive.ObjectID `json:"user_id" bson:"userId"`
 	IsArchived bool               `json:"is_archived" bson:"isArchived"`
+	// Version is the expected current version taken from If-Match, nil to skip the check.
+	Version *int64 `json:"-" bson:"-"`
 }
@@ -3,6 +3,8 @@ package repository
 import (
 	"context"
 	"errors"
+	"regexp"
+	"strings"
 	"time"
 
 	"noto/internal/services/books/model"
@@ -16,23 +18,145 @@ import (
 
 type BookRepository interface {
 	CreateBook(book *model.BookCreate) (*model.BookCreate, error)
-	GetBooks(userId primitive.ObjectID, isArchived bool, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBooks(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBooksByCursor(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, cursor string, limit int) (*model.CursorBookResponse, error)
 	GetBook(userId primitive.ObjectID, bookId primitive.ObjectID) (*model.BookResponse, error)
 	UpdateBook(book *model.BookUpdate) (*model.BookResponse, error)
 	ArchiveBook(book *model.ArchiveBook) (*model.BookResponse, error)
+	IterateBooks(userId primitive.ObjectID, fn func(book *model.BookResponse) error) error
+	DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
+	EnsureIndexes() error
 }
 
 type BookRepositoryImpl struct {
-	books *mongo.Collection
+	books       *mongo.Collection
+	labels      *mongo.Collection
+	book_labels *mongo.Collection
 }
 
 func NewBookRepository(db *mongo.Database) BookRepository {
-	return &BookRepositoryImpl{books: db.Collection("books")}
+	return &BookRepositoryImpl{
+		books:       db.Collection("books"),
+		labels:      db.Collection("labels"),
+		book_labels: db.Collection("book_labels"),
+	}
+}
+
+// defaultBookSort lists the most recently updated books first.
+var defaultBookSort = bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}
+
+// bookAgregate never returns books in the trash, see the trash service for those.
+// Labels are only looked up for the books of the requested page.
+func bookAgregate(matchCondition bson.D, sort bson.D, page int, limit int, usePagination bool) mongo.Pipeline {
+	matchCondition = append(matchCondition, bson.E{Key: "deletedAt", Value: nil})
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: matchCondition}},
+		{{Key: "$sort", Value: sort}},
+	}
+
+	if usePagination {
+		return append(pipeline,
+			bson.D{{Key: "$facet", Value: utils.PaginationAggregateWith(page, limit, bookLabelStages())}},
+			bson.D{{Key: "$unwind", Value: "$metadata"}},
+		)
+	}
+
+	return append(pipeline, bookLabelStages()...)
 }
 
-func bookAgregate(matchCondition bson.D, page int, limit int, usePagination bool) mongo.Pipeline {
+// bookKeysetAgregate fetches one page after the keyset condition plus one
+// extra book to tell whether another page follows. Labels are only looked up
+// for the books of that page.
+func bookKeysetAgregate(matchCondition bson.D, sort bson.D, keyset bson.M, limit int) mongo.Pipeline {
+	matchCondition = append(matchCondition,
+		bson.E{Key: "deletedAt", Value: nil},
+		bson.E{Key: "$and", Value: bson.A{keyset}},
+	)
 	pipeline := mongo.Pipeline{
 		{{Key: "$match", Value: matchCondition}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$limit", Value: limit + 1}},
+	}
+
+	return append(pipeline, bookLabelStages()...)
+}
+
+// labelMatch resolves the label filter to the ids of the matching books, so
+// books are filtered by an indexed _id condition instead of looking up the
+// labels of every book first. A label name also matches the labels nested
+// under it, as on /labels/{labelName}/books.
+func (r *BookRepositoryImpl) labelMatch(userId primitive.ObjectID, labels *utils.LabelFilter) (bson.D, error) {
+	names := labels.Names()
+	if len(names) == 0 {
+		return bson.D{}, nil
+	}
+
+	prefixes := make([]string, 0, len(names))
+	for _, name := range names {
+		prefixes = append(prefixes, regexp.QuoteMeta(name+"/"))
+	}
+
+	filter := bson.M{
+		"userId": userId,
+		"$or": bson.A{
+			bson.M{"name": bson.M{"$in": names}},
+			bson.M{"name": bson.M{"$regex": "^(?:" + strings.Join(prefixes, "|") + ")"}},
+		},
+	}
+	cursor, err := r.labels.Find(context.Background(), filter, options.Find().SetProjection(bson.M{"name": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var found []struct {
+		ID   primitive.ObjectID `bson:"_id"`
+		Name string             `bson:"name"`
+	}
+	if err := cursor.All(context.Background(), &found); err != nil {
+		return nil, err
+	}
+
+	// Each label counts for every filtered name it equals or is nested under.
+	labelNames := make(map[primitive.ObjectID][]string, len(found))
+	labelIds := make([]primitive.ObjectID, 0, len(found))
+	for _, label := range found {
+		for _, name := range names {
+			if label.Name == name || strings.HasPrefix(label.Name, name+"/") {
+				labelNames[label.ID] = append(labelNames[label.ID], name)
+			}
+		}
+		labelIds = append(labelIds, label.ID)
+	}
+
+	tagged := map[string][]primitive.ObjectID{}
+	if len(labelIds) > 0 {
+		cursor, err := r.book_labels.Find(context.Background(), bson.M{"labelId": bson.M{"$in": labelIds}},
+			options.Find().SetProjection(bson.M{"bookId": 1, "labelId": 1}))
+		if err != nil {
+			return nil, err
+		}
+
+		var links []struct {
+			BookId  primitive.ObjectID `bson:"bookId"`
+			LabelId primitive.ObjectID `bson:"labelId"`
+		}
+		if err := cursor.All(context.Background(), &links); err != nil {
+			return nil, err
+		}
+
+		for _, link := range links {
+			for _, name := range labelNames[link.LabelId] {
+				tagged[name] = append(tagged[name], link.BookId)
+			}
+		}
+	}
+
+	return labels.Match(tagged), nil
+}
+
+// bookLabelStages embeds the labels of each book.
+func bookLabelStages() mongo.Pipeline {
+	return mongo.Pipeline{
 		{{Key: "$lookup", Value: bson.M{
 			"from":         "book_labels",
 			"localField":   "_id",
@@ -50,23 +174,14 @@ func bookAgregate(matchCondition bson.D, page int, limit int, usePagination bool
 			"labels.createdAt": 0,
 			"labels.updatedAt": 0,
 		}}},
-		{{Key: "$sort", Value: bson.M{"updatedAt": -1}}},
 	}
-
-	if usePagination {
-		pipeline = append(pipeline,
-			bson.D{{Key: "$facet", Value: utils.PaginationAggregate(page, limit)}},
-			bson.D{{Key: "$unwind", Value: "$metadata"}},
-		)
-	}
-
-	return pipeline
 }
 
 func (r *BookRepositoryImpl) CreateBook(book *model.BookCreate) (*model.BookCreate, error) {
 	book.CreatedAt = time.Now()
 	book.UpdatedAt = time.Now()
 	book.IsArchived = book.IsArchived || false
+	book.Version = 1
 
 	newBook, err := r.books.InsertOne(context.Background(), book)
 	if err != nil {
@@ -78,14 +193,22 @@ func (r *BookRepositoryImpl) CreateBook(book *model.BookCreate) (*model.BookCrea
 	return book, nil
 }
 
-func (r *BookRepositoryImpl) GetBooks(userId primitive.ObjectID, isArchived bool, page int, limit int) (*model.PaginatedBookResponse, error) {
+func (r *BookRepositoryImpl) GetBooks(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, page int, limit int) (*model.PaginatedBookResponse, error) {
 	var books []model.PaginatedBookResponse
 
 	filter := bson.D{
 		{Key: "userId", Value: userId},
 		{Key: "isArchived", Value: isArchived},
 	}
-	pipeline := bookAgregate(filter, page, limit, true)
+	filter = append(filter, query.Match("title")...)
+
+	labelMatch, err := r.labelMatch(userId, labels)
+	if err != nil {
+		return nil, err
+	}
+	filter = append(filter, labelMatch...)
+
+	pipeline := bookAgregate(filter, query.Sort(defaultBookSort), page, limit, true)
 
 	cursor, err := r.books.Aggregate(context.Background(), pipeline)
 	if err != nil {
@@ -105,6 +228,54 @@ func (r *BookRepositoryImpl) GetBooks(userId primitive.ObjectID, isArchived bool
 	return &books[0], nil
 }
 
+// GetBooksByCursor is the keyset paginated variant of GetBooks. Its cost does
+// not grow with the page depth and concurrent inserts do not shift pages.
+func (r *BookRepositoryImpl) GetBooksByCursor(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, cursor string, limit int) (*model.CursorBookResponse, error) {
+	sort := query.Sort(defaultBookSort)
+	keyset, err := utils.KeysetMatch(sort, cursor, utils.KeysetDocument(r.books, bson.M{"userId": userId}))
+	if err != nil {
+		return nil, err
+	}
+
+	filter := bson.D{
+		{Key: "userId", Value: userId},
+		{Key: "isArchived", Value: isArchived},
+	}
+	filter = append(filter, query.Match("title")...)
+
+	labelMatch, err := r.labelMatch(userId, labels)
+	if err != nil {
+		return nil, err
+	}
+	filter = append(filter, labelMatch...)
+
+	pipeline := bookKeysetAgregate(filter, sort, keyset, limit)
+
+	results, err := r.books.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.Raw
+	if err := results.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	page, next, err := utils.KeysetPage(sort, documents, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(page))
+	for i, document := range page {
+		if err := bson.Unmarshal(document, &books[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.CursorBookResponse{Data: books, NextCursor: next}, nil
+}
+
 func (r *BookRepositoryImpl) GetBook(userId primitive.ObjectID, bookId primitive.ObjectID) (*model.BookResponse, error) {
 	var book []model.BookResponse
 
@@ -112,7 +283,7 @@ func (r *BookRepositoryImpl) GetBook(userId primitive.ObjectID, bookId primitive
 		{Key: "userId", Value: userId},
 		{Key: "_id", Value: bookId},
 	}
-	pipeline := bookAgregate(filter, 0, 0, false)
+	pipeline := bookAgregate(filter, defaultBookSort, 0, 0, false)
 
 	cursor, err := r.books.Aggregate(context.Background(), pipeline)
 	if err != nil {
@@ -130,20 +301,55 @@ func (r *BookRepositoryImpl) GetBook(userId primitive.ObjectID, bookId primitive
 }
 
 func (r *BookRepositoryImpl) UpdateBook(book *model.BookUpdate) (*model.BookResponse, error) {
-	filter := bson.M{"userId": book.UserId, "_id": book.ID}
+	filter := bson.M{"userId": book.UserId, "_id": book.ID, "deletedAt": nil}
 	update := bson.M{
 		"$set": bson.M{
 			"title":     book.Title,
 			"updatedAt": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	return r.updateVersioned(filter, book.Version, update)
+}
+
+func (r *BookRepositoryImpl) ArchiveBook(book *model.ArchiveBook) (*model.BookResponse, error) {
+	filter := bson.M{"userId": book.UserId, "_id": book.ID, "deletedAt": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"isArchived": book.IsArchived,
+			"updatedAt":  time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	result := r.books.FindOneAndUpdate(context.Background(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	return r.updateVersioned(filter, book.Version, update)
+}
+
+// updateVersioned applies update only while the book is still at the expected
+// version, telling a stale version apart from a missing book.
+func (r *BookRepositoryImpl) updateVersioned(filter bson.M, version *int64, update bson.M) (*model.BookResponse, error) {
+	versioned := bson.M{}
+	for key, value := range filter {
+		versioned[key] = value
+	}
+	utils.MatchVersion(versioned, version)
+
+	result := r.books.FindOneAndUpdate(context.Background(), versioned, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
 	if result.Err() != nil {
-		if result.Err() == mongo.ErrNoDocuments {
-			return nil, errors.New("book not found")
+		if result.Err() != mongo.ErrNoDocuments {
+			return nil, result.Err()
 		}
-		return nil, result.Err()
+		if version != nil {
+			count, err := r.books.CountDocuments(context.Background(), filter)
+			if err != nil {
+				return nil, err
+			}
+			if count > 0 {
+				return nil, errors.New("version conflict")
+			}
+		}
+		return nil, errors.New("book not found")
 	}
 
 	var updatedBook model.BookResponse
@@ -151,31 +357,63 @@ func (r *BookRepositoryImpl) UpdateBook(book *model.BookUpdate) (*model.BookResp
 	if err != nil {
 		return nil, err
 	}
+
 	return &updatedBook, nil
 }
 
-func (r *BookRepositoryImpl) ArchiveBook(book *model.ArchiveBook) (*model.BookResponse, error) {
-	filter := bson.M{"userId": book.UserId, "_id": book.ID}
+// DeleteBook moves the book to the trash. Its notes and labels are kept so a
+// restore brings everything back; they are removed when the trash is purged.
+func (r *BookRepositoryImpl) DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	filter := bson.M{"_id": bookId, "userId": userId, "deletedAt": nil}
 	update := bson.M{
 		"$set": bson.M{
-			"isArchived": book.IsArchived,
-			"updatedAt":  time.Now(),
+			"deletedAt": time.Now(),
 		},
 	}
 
-	result := r.books.FindOneAndUpdate(context.Background(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
-	if result.Err() != nil {
-		if result.Err() == mongo.ErrNoDocuments {
-			return nil, errors.New("book not found")
-		}
-		return nil, result.Err()
+	updated, err := r.books.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return err
 	}
 
-	var updatedBook model.BookResponse
-	err := result.Decode(&updatedBook)
+	if updated.MatchedCount == 0 {
+		return errors.New("book not found")
+	}
+
+	return nil
+}
+
+// IterateBooks calls fn for every book of the user, archived or not, without
+// loading them all into memory.
+func (r *BookRepositoryImpl) IterateBooks(userId primitive.ObjectID, fn func(book *model.BookResponse) error) error {
+	filter := bson.D{{Key: "userId", Value: userId}}
+	pipeline := bookAgregate(filter, defaultBookSort, 0, 0, false)
+
+	cursor, err := r.books.Aggregate(context.Background(), pipeline)
 	if err != nil {
-		return nil, err
+		return err
 	}
+	defer cursor.Close(context.Background())
 
-	return &updatedBook, nil
+	for cursor.Next(context.Background()) {
+		var book model.BookResponse
+		if err := cursor.Decode(&book); err != nil {
+			return err
+		}
+
+		if err := fn(&book); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// EnsureIndexes creates the index behind the default book listing.
+func (r *BookRepositoryImpl) EnsureIndexes() error {
+	_, err := r.books.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "userId", Value: 1}, {Key: "isArchived", Value: 1}, {Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}},
+	})
+
+	return err
 }
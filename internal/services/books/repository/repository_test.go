@@ -4,6 +4,7 @@ import (
 	"context"
 	"noto/internal/config"
 	"noto/internal/services/books/model"
+	"noto/internal/utils"
 	"testing"
 	"time"
 
@@ -45,6 +46,12 @@ func cleanupDatabase() {
 	if err != nil {
 		panic(err)
 	}
+
+	for _, name := range []string{"labels", "book_labels"} {
+		if _, err := config.DB.Collection(name).DeleteMany(context.Background(), bson.M{"userId": userId}); err != nil {
+			panic(err)
+		}
+	}
 }
 
 func TestCreateBook(t *testing.T) {
@@ -64,7 +71,7 @@ func TestCreateBook(t *testing.T) {
 }
 
 func TestGetBooks(t *testing.T) {
-	res, err := repo.GetBooks(userId, false, 1, 10)
+	res, err := repo.GetBooks(userId, false, &utils.ListQuery{}, &utils.LabelFilter{}, 1, 10)
 
 	require.NoError(t, err, "Failed to get books")
 	assert.NotNil(t, res, "Data should not nil")
@@ -98,6 +105,85 @@ func TestUpdateBook(t *testing.T) {
 	assert.Equal(t, title, res.Title, "Title result should match expected")
 }
 
+func TestUpdateBookVersion(t *testing.T) {
+	current, err := repo.GetBook(userId, bookId)
+	require.NoError(t, err, "Failed to get book")
+
+	stale := current.Version - 1
+	_, err = repo.UpdateBook(&model.BookUpdate{ID: bookId, UserId: userId, Title: "Stale", Version: &stale})
+	require.Error(t, err)
+	assert.Equal(t, "version conflict", err.Error())
+
+	res, err := repo.UpdateBook(&model.BookUpdate{ID: bookId, UserId: userId, Title: "Fresh", Version: &current.Version})
+	require.NoError(t, err, "Failed to update book")
+	assert.Equal(t, current.Version+1, res.Version, "Version should be incremented")
+}
+
+func TestGetBooksByCursor(t *testing.T) {
+	res, err := repo.GetBooksByCursor(userId, false, &utils.ListQuery{}, &utils.LabelFilter{}, "", 10)
+	require.NoError(t, err, "Failed to get books")
+	require.Len(t, res.Data, 1)
+	assert.Nil(t, res.NextCursor, "A single book fits on one page")
+
+	_, err = repo.GetBooksByCursor(userId, false, &utils.ListQuery{}, &utils.LabelFilter{}, "bogus", 10)
+	require.Error(t, err)
+	assert.Equal(t, "invalid cursor", err.Error())
+}
+
+func TestGetBooksByLabels(t *testing.T) {
+	otherId := primitive.NewObjectID()
+	_, err := repo.CreateBook(&model.BookCreate{ID: otherId, UserId: userId, Title: "Other Book"})
+	require.NoError(t, err, "Failed to create book")
+
+	labels := map[string]primitive.ObjectID{"work": primitive.NewObjectID(), "urgent": primitive.NewObjectID(), "done": primitive.NewObjectID(), "work/acme": primitive.NewObjectID()}
+	for name, labelId := range labels {
+		_, err := config.DB.Collection("labels").InsertOne(context.Background(), bson.M{"_id": labelId, "userId": userId, "name": name})
+		require.NoError(t, err)
+	}
+
+	links := []bson.M{
+		{"userId": userId, "bookId": bookId, "labelId": labels["work"]},
+		{"userId": userId, "bookId": bookId, "labelId": labels["urgent"]},
+		{"userId": userId, "bookId": otherId, "labelId": labels["work"]},
+		{"userId": userId, "bookId": otherId, "labelId": labels["done"]},
+		{"userId": userId, "bookId": otherId, "labelId": labels["work/acme"]},
+	}
+	for _, link := range links {
+		_, err := config.DB.Collection("book_labels").InsertOne(context.Background(), link)
+		require.NoError(t, err)
+	}
+
+	testCases := []struct {
+		name     string
+		labels   utils.LabelFilter
+		expected []string
+	}{
+		{"All", utils.LabelFilter{Labels: []string{"work", "urgent"}, MatchAll: true}, []string{bookId.Hex()}},
+		{"Any", utils.LabelFilter{Labels: []string{"urgent", "done"}}, []string{bookId.Hex(), otherId.Hex()}},
+		{"Exclude", utils.LabelFilter{Labels: []string{"work"}, MatchAll: true, Exclude: []string{"done"}}, []string{bookId.Hex()}},
+		{"Nested", utils.LabelFilter{Labels: []string{"urgent", "work"}, MatchAll: true, Exclude: []string{"work/acme"}}, []string{bookId.Hex()}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			res, err := repo.GetBooks(userId, false, &utils.ListQuery{}, &testCase.labels, 1, 10)
+			require.NoError(t, err, "Failed to get books")
+
+			ids := []string{}
+			for _, book := range res.Data {
+				ids = append(ids, book.ID)
+			}
+			assert.ElementsMatch(t, testCase.expected, ids)
+
+			page, err := repo.GetBooksByCursor(userId, false, &utils.ListQuery{}, &testCase.labels, "", 10)
+			require.NoError(t, err, "Failed to get books")
+			assert.Len(t, page.Data, len(testCase.expected))
+		})
+	}
+
+	require.NoError(t, repo.DeleteBook(userId, otherId))
+}
+
 func TestArchiveBook(t *testing.T) {
 	archive := true
 	archived := model.ArchiveBook{
@@ -110,3 +196,95 @@ func TestArchiveBook(t *testing.T) {
 	require.NoError(t, err, "Failed to archive book")
 	assert.Equal(t, archive, res.IsArchived, "IsArchived result should match expected")
 }
+
+func TestDeleteBook(t *testing.T) {
+	err := repo.DeleteBook(userId, bookId)
+	require.NoError(t, err, "Failed to delete book")
+
+	err = repo.DeleteBook(userId, bookId)
+	require.Error(t, err)
+	assert.Equal(t, "book not found", err.Error())
+}
+
+// The benchmarks below compare page/limit and keyset pagination over a seeded
+// user with benchBooks books, reading the first page and a page deep in the
+// listing.
+const (
+	benchBooks = 20000
+	benchLimit = 20
+	benchDepth = benchBooks / benchLimit * 9 / 10
+)
+
+func seedBenchmarkBooks(b *testing.B) primitive.ObjectID {
+	b.Helper()
+
+	benchUserId := primitive.NewObjectID()
+	start := time.Now().Add(-benchBooks * time.Second)
+
+	documents := make([]interface{}, 0, benchBooks)
+	for i := 0; i < benchBooks; i++ {
+		created := start.Add(time.Duration(i) * time.Second)
+		documents = append(documents, model.BookCreate{
+			UserId:    benchUserId,
+			Title:     "Benchmark Book",
+			CreatedAt: created,
+			UpdatedAt: created,
+			Version:   1,
+		})
+	}
+
+	_, err := config.DB.Collection("books").InsertMany(context.Background(), documents)
+	require.NoError(b, err)
+	require.NoError(b, repo.EnsureIndexes())
+
+	b.Cleanup(func() {
+		_, err := config.DB.Collection("books").DeleteMany(context.Background(), bson.M{"userId": benchUserId})
+		require.NoError(b, err)
+	})
+
+	return benchUserId
+}
+
+func BenchmarkGetBooks(b *testing.B) {
+	benchUserId := seedBenchmarkBooks(b)
+	query := &utils.ListQuery{}
+	noLabels := &utils.LabelFilter{}
+
+	deepCursor := ""
+	for i := 1; i < benchDepth; i++ {
+		res, err := repo.GetBooksByCursor(benchUserId, false, query, noLabels, deepCursor, benchLimit)
+		require.NoError(b, err)
+		require.NotNil(b, res.NextCursor)
+		deepCursor = *res.NextCursor
+	}
+
+	b.Run("Offset/First", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := repo.GetBooks(benchUserId, false, query, noLabels, 1, benchLimit)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Offset/Deep", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			res, err := repo.GetBooks(benchUserId, false, query, noLabels, benchDepth, benchLimit)
+			require.NoError(b, err)
+			require.Len(b, res.Data, benchLimit)
+		}
+	})
+
+	b.Run("Cursor/First", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			_, err := repo.GetBooksByCursor(benchUserId, false, query, noLabels, "", benchLimit)
+			require.NoError(b, err)
+		}
+	})
+
+	b.Run("Cursor/Deep", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			res, err := repo.GetBooksByCursor(benchUserId, false, query, noLabels, deepCursor, benchLimit)
+			require.NoError(b, err)
+			require.Len(b, res.Data, benchLimit)
+		}
+	})
+}
@@ -1,10 +1,13 @@
 package books_router
 
 import (
+	"log"
 	"noto/internal/config"
+	"noto/internal/middleware"
 	"noto/internal/services/books/handler"
 	"noto/internal/services/books/repository"
 	"noto/internal/services/books/service"
+	"noto/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -15,9 +18,17 @@ func BooksRouter(router fiber.Router) {
 	serv := service.NewBookService(repo)
 	hand := handler.NewBookHandler(serv)
 
-	router.Post("/books", hand.CreateBook)
-	router.Put("/books/:bookId", hand.UpdateBook)
-	router.Get("/books", hand.GetBooks)
-	router.Get("/books/:bookId", hand.GetBook)
-	router.Patch("/books/:bookId", hand.ArchiveBook)
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create book indexes:", err)
+	}
+
+	read := middleware.RequireScope(utils.ScopeBooksRead)
+	write := middleware.RequireScope(utils.ScopeBooksWrite)
+
+	router.Post("/books", write, hand.CreateBook)
+	router.Put("/books/:bookId", write, hand.UpdateBook)
+	router.Get("/books", read, hand.GetBooks)
+	router.Get("/books/:bookId", read, hand.GetBook)
+	router.Patch("/books/:bookId", write, hand.ArchiveBook)
+	router.Delete("/books/:bookId", write, hand.DeleteBook)
 }
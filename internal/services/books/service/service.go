@@ -3,16 +3,19 @@ package service
 import (
 	"noto/internal/services/books/model"
 	"noto/internal/services/books/repository"
+	"noto/internal/utils"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type BookService interface {
 	CreateBook(book *model.BookCreate) (*model.BookCreate, error)
-	GetBooks(userId primitive.ObjectID, isArchived bool, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBooks(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBooksByCursor(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, cursor string, limit int) (*model.CursorBookResponse, error)
 	GetBook(userId primitive.ObjectID, bookId primitive.ObjectID) (*model.BookResponse, error)
 	UpdateBook(book *model.BookUpdate) (*model.BookResponse, error)
 	ArchiveBook(book *model.ArchiveBook) (*model.BookResponse, error)
+	DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
 }
 
 type BookServiceImpl struct {
@@ -27,8 +30,12 @@ func (r *BookServiceImpl) CreateBook(book *model.BookCreate) (*model.BookCreate,
 	return r.bookRepo.CreateBook(book)
 }
 
-func (r *BookServiceImpl) GetBooks(userId primitive.ObjectID, isArchived bool, page int, limit int) (*model.PaginatedBookResponse, error) {
-	return r.bookRepo.GetBooks(userId, isArchived, page, limit)
+func (r *BookServiceImpl) GetBooks(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, page int, limit int) (*model.PaginatedBookResponse, error) {
+	return r.bookRepo.GetBooks(userId, isArchived, query, labels, page, limit)
+}
+
+func (r *BookServiceImpl) GetBooksByCursor(userId primitive.ObjectID, isArchived bool, query *utils.ListQuery, labels *utils.LabelFilter, cursor string, limit int) (*model.CursorBookResponse, error) {
+	return r.bookRepo.GetBooksByCursor(userId, isArchived, query, labels, cursor, limit)
 }
 
 func (r *BookServiceImpl) GetBook(userId primitive.ObjectID, bookId primitive.ObjectID) (*model.BookResponse, error) {
@@ -42,3 +49,7 @@ func (s *BookServiceImpl) UpdateBook(book *model.BookUpdate) (*model.BookRespons
 func (s *BookServiceImpl) ArchiveBook(book *model.ArchiveBook) (*model.BookResponse, error) {
 	return s.bookRepo.ArchiveBook(book)
 }
+
+func (s *BookServiceImpl) DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	return s.bookRepo.DeleteBook(userId, bookId)
+}
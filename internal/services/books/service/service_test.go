@@ -5,6 +5,7 @@ import (
 	"noto/internal/config"
 	"noto/internal/services/books/model"
 	"noto/internal/services/books/repository"
+	"noto/internal/utils"
 	"testing"
 	"time"
 
@@ -67,7 +68,7 @@ func TestCreateBook(t *testing.T) {
 }
 
 func TestGetBooks(t *testing.T) {
-	res, err := serv.GetBooks(userId, false, 1, 10)
+	res, err := serv.GetBooks(userId, false, &utils.ListQuery{}, &utils.LabelFilter{}, 1, 10)
 
 	require.NoError(t, err, "Failed to get books")
 	assert.NotNil(t, res, "Data should not nil")
@@ -113,3 +114,12 @@ func TestArchiveBook(t *testing.T) {
 	require.NoError(t, err, "Failed to archive book")
 	assert.Equal(t, archive, res.IsArchived, "IsArchived result should match expected")
 }
+
+func TestDeleteBook(t *testing.T) {
+	err := serv.DeleteBook(userId, bookId)
+	require.NoError(t, err, "Failed to delete book")
+
+	err = serv.DeleteBook(userId, bookId)
+	require.Error(t, err)
+	assert.Equal(t, "book not found", err.Error())
+}
@@ -0,0 +1,64 @@
+package handler
+
+import (
+	"bufio"
+	"log"
+	_ "noto/internal/common"
+	"noto/internal/services/export/service"
+	"noto/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type ExportHandler interface {
+	Export(c *fiber.Ctx) error
+}
+
+type ExportHandlerImpl struct {
+	exportService service.ExportService
+}
+
+func NewExportHandler(exportService service.ExportService) ExportHandler {
+	return &ExportHandlerImpl{exportService: exportService}
+}
+
+// Export
+// @Summary		Export account data
+// @Description	Download a ZIP archive with the profile, labels, books and notes as JSON and every book rendered as Markdown
+// @Tags		Export
+// @Security 	BearerAuth
+// @Produce		application/zip
+// @Param 		Authorization header string false "Bearer token"
+// @Success		200		{file}		file
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/export [get]
+func (s *ExportHandlerImpl) Export(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	write, err := s.exportService.Export(userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to export: "+err.Error())
+	}
+
+	c.Set(fiber.HeaderContentType, "application/zip")
+	c.Set(fiber.HeaderContentDisposition, `attachment; filename="noto-export-`+time.Now().Format("2006-01-02")+`.zip"`)
+	c.Context().SetBodyStreamWriter(func(w *bufio.Writer) {
+		// The status is already sent at this point, a failure can only cut the archive short.
+		if err := write(w); err != nil {
+			log.Println("Failed to write export:", err)
+		}
+		w.Flush()
+	})
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package export_router
+
+import (
+	"noto/internal/config"
+	"noto/internal/middleware"
+	book_repository "noto/internal/services/books/repository"
+	"noto/internal/services/export/handler"
+	"noto/internal/services/export/service"
+	label_repository "noto/internal/services/labels/repository"
+	note_repository "noto/internal/services/notes/repository"
+	user_repository "noto/internal/services/users/repository"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func ExportRouter(router fiber.Router) {
+	var serv = service.NewExportService(
+		user_repository.NewUserRepository(config.DB),
+		book_repository.NewBookRepository(config.DB),
+		note_repository.NewNoteRepository(config.DB),
+		label_repository.NewLabelRepository(config.DB),
+	)
+	var hand = handler.NewExportHandler(serv)
+
+	var read = middleware.RequireScope(utils.ScopeAccountRead, utils.ScopeBooksRead, utils.ScopeNotesRead, utils.ScopeLabelsRead)
+
+	router.Get("/export", read, hand.Export)
+}
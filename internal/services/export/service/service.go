@@ -0,0 +1,227 @@
+package service
+
+import (
+	"archive/zip"
+	"encoding/json"
+	"fmt"
+	"io"
+	book_model "noto/internal/services/books/model"
+	book_repository "noto/internal/services/books/repository"
+	label_repository "noto/internal/services/labels/repository"
+	note_model "noto/internal/services/notes/model"
+	note_repository "noto/internal/services/notes/repository"
+	user_repository "noto/internal/services/users/repository"
+	"regexp"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+// WriteFunc writes the archive to w. It is returned separately from Export so
+// that missing accounts are reported before the response starts streaming.
+type WriteFunc func(w io.Writer) error
+
+type ExportService interface {
+	Export(userId primitive.ObjectID) (WriteFunc, error)
+}
+
+type ExportServiceImpl struct {
+	userRepo  user_repository.UserRepository
+	bookRepo  book_repository.BookRepository
+	noteRepo  note_repository.NoteRepository
+	labelRepo label_repository.LabelRepository
+}
+
+func NewExportService(userRepo user_repository.UserRepository, bookRepo book_repository.BookRepository, noteRepo note_repository.NoteRepository, labelRepo label_repository.LabelRepository) ExportService {
+	return &ExportServiceImpl{userRepo: userRepo, bookRepo: bookRepo, noteRepo: noteRepo, labelRepo: labelRepo}
+}
+
+func (s *ExportServiceImpl) Export(userId primitive.ObjectID) (WriteFunc, error) {
+	user, err := s.userRepo.GetUser(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	labels, err := s.labelRepo.GetLabels(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	location := time.UTC
+	if user.Timezone != "" {
+		if loc, err := time.LoadLocation(user.Timezone); err == nil {
+			location = loc
+		}
+	}
+
+	return func(w io.Writer) error {
+		zw := zip.NewWriter(w)
+
+		if err := writeJSONFile(zw, "profile.json", user); err != nil {
+			return err
+		}
+
+		if err := writeJSONFile(zw, "labels.json", labels); err != nil {
+			return err
+		}
+
+		if err := s.writeBooks(zw, userId); err != nil {
+			return err
+		}
+
+		if err := s.writeNotes(zw, userId); err != nil {
+			return err
+		}
+
+		if err := s.writeMarkdown(zw, userId, location); err != nil {
+			return err
+		}
+
+		return zw.Close()
+	}, nil
+}
+
+func (s *ExportServiceImpl) writeBooks(zw *zip.Writer, userId primitive.ObjectID) error {
+	f, err := zw.Create("books.json")
+	if err != nil {
+		return err
+	}
+
+	array := newJSONArrayWriter(f)
+	err = s.bookRepo.IterateBooks(userId, func(book *book_model.BookResponse) error {
+		return array.Write(book)
+	})
+	if err != nil {
+		return err
+	}
+
+	return array.Close()
+}
+
+func (s *ExportServiceImpl) writeNotes(zw *zip.Writer, userId primitive.ObjectID) error {
+	f, err := zw.Create("notes.json")
+	if err != nil {
+		return err
+	}
+
+	array := newJSONArrayWriter(f)
+	err = s.noteRepo.IterateNotes(userId, primitive.NilObjectID, func(note *note_model.NoteResponse) error {
+		return array.Write(note)
+	})
+	if err != nil {
+		return err
+	}
+
+	return array.Close()
+}
+
+func (s *ExportServiceImpl) writeMarkdown(zw *zip.Writer, userId primitive.ObjectID, location *time.Location) error {
+	return s.bookRepo.IterateBooks(userId, func(book *book_model.BookResponse) error {
+		f, err := zw.Create("markdown/" + markdownFileName(book))
+		if err != nil {
+			return err
+		}
+
+		if _, err := io.WriteString(f, renderBookHeader(book)); err != nil {
+			return err
+		}
+
+		bookId, err := primitive.ObjectIDFromHex(book.ID)
+		if err != nil {
+			return err
+		}
+
+		return s.noteRepo.IterateNotes(userId, bookId, func(note *note_model.NoteResponse) error {
+			_, err := io.WriteString(f, renderNote(note, location))
+			return err
+		})
+	})
+}
+
+func writeJSONFile(zw *zip.Writer, name string, v interface{}) error {
+	f, err := zw.Create(name)
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(f)
+	encoder.SetIndent("", "  ")
+
+	return encoder.Encode(v)
+}
+
+// jsonArrayWriter encodes a JSON array one element at a time so large
+// collections never have to be held in memory.
+type jsonArrayWriter struct {
+	w     io.Writer
+	count int
+}
+
+func newJSONArrayWriter(w io.Writer) *jsonArrayWriter {
+	return &jsonArrayWriter{w: w}
+}
+
+func (a *jsonArrayWriter) Write(v interface{}) error {
+	b, err := json.MarshalIndent(v, "  ", "  ")
+	if err != nil {
+		return err
+	}
+
+	prefix := ",\n  "
+	if a.count == 0 {
+		prefix = "[\n  "
+	}
+	a.count++
+
+	if _, err := io.WriteString(a.w, prefix); err != nil {
+		return err
+	}
+
+	_, err = a.w.Write(b)
+	return err
+}
+
+func (a *jsonArrayWriter) Close() error {
+	if a.count == 0 {
+		_, err := io.WriteString(a.w, "[]\n")
+		return err
+	}
+
+	_, err := io.WriteString(a.w, "\n]\n")
+	return err
+}
+
+var nonSlugChars = regexp.MustCompile(`[^a-z0-9]+`)
+
+func markdownFileName(book *book_model.BookResponse) string {
+	slug := strings.Trim(nonSlugChars.ReplaceAllString(strings.ToLower(book.Title), "-"), "-")
+	if slug == "" {
+		return book.ID + ".md"
+	}
+
+	return slug + "-" + book.ID + ".md"
+}
+
+func renderBookHeader(book *book_model.BookResponse) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", book.Title)
+
+	if len(book.Labels) > 0 {
+		names := make([]string, 0, len(book.Labels))
+		for _, label := range book.Labels {
+			names = append(names, label.Name)
+		}
+		fmt.Fprintf(&b, "Labels: %s\n\n", strings.Join(names, ", "))
+	}
+
+	if book.IsArchived {
+		b.WriteString("_Archived_\n\n")
+	}
+
+	return b.String()
+}
+
+func renderNote(note *note_model.NoteResponse, location *time.Location) string {
+	return fmt.Sprintf("---\n\n_%s_\n\n%s\n\n", note.CreatedAt.In(location).Format("2006-01-02 15:04"), strings.TrimSpace(note.Text))
+}
@@ -0,0 +1,140 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"io"
+	book_model "noto/internal/services/books/model"
+	book_repository "noto/internal/services/books/repository"
+	label_model "noto/internal/services/labels/model"
+	label_repository "noto/internal/services/labels/repository"
+	note_model "noto/internal/services/notes/model"
+	note_repository "noto/internal/services/notes/repository"
+	user_model "noto/internal/services/users/model"
+	user_repository "noto/internal/services/users/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubUserRepo struct {
+	user_repository.UserRepository
+	user *user_model.UserResponse
+}
+
+func (r *stubUserRepo) GetUser(userId primitive.ObjectID) (*user_model.UserResponse, error) {
+	return r.user, nil
+}
+
+type stubBookRepo struct {
+	book_repository.BookRepository
+	books []book_model.BookResponse
+}
+
+func (r *stubBookRepo) IterateBooks(userId primitive.ObjectID, fn func(book *book_model.BookResponse) error) error {
+	for i := range r.books {
+		if err := fn(&r.books[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stubNoteRepo struct {
+	note_repository.NoteRepository
+	notes []note_model.NoteResponse
+}
+
+func (r *stubNoteRepo) IterateNotes(userId primitive.ObjectID, bookId primitive.ObjectID, fn func(note *note_model.NoteResponse) error) error {
+	for i := range r.notes {
+		if !bookId.IsZero() && r.notes[i].BookId != bookId {
+			continue
+		}
+		if err := fn(&r.notes[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+type stubLabelRepo struct {
+	label_repository.LabelRepository
+}
+
+func (r *stubLabelRepo) GetLabels(userId primitive.ObjectID) ([]label_model.LabelResponse, error) {
+	return []label_model.LabelResponse{{ID: primitive.NewObjectID(), Name: "work"}}, nil
+}
+
+func TestExport(t *testing.T) {
+	userId := primitive.NewObjectID()
+	bookId := primitive.NewObjectID()
+	otherBookId := primitive.NewObjectID()
+	createdAt := time.Date(2024, 5, 1, 8, 30, 0, 0, time.UTC)
+
+	serv := NewExportService(
+		&stubUserRepo{user: &user_model.UserResponse{ID: userId.Hex(), Name: "Jane", Timezone: "Asia/Jakarta"}},
+		&stubBookRepo{books: []book_model.BookResponse{
+			{ID: bookId.Hex(), Title: "My Journal", IsArchived: true, Labels: []book_model.Label{{Name: "work"}}},
+			{ID: otherBookId.Hex(), Title: "!!!"},
+		}},
+		&stubNoteRepo{notes: []note_model.NoteResponse{
+			{ID: primitive.NewObjectID().Hex(), BookId: bookId, Text: "first note", CreatedAt: createdAt},
+			{ID: primitive.NewObjectID().Hex(), BookId: otherBookId, Text: "other note", CreatedAt: createdAt},
+		}},
+		&stubLabelRepo{},
+	)
+
+	write, err := serv.Export(userId)
+	require.NoError(t, err)
+
+	var buf bytes.Buffer
+	require.NoError(t, write(&buf))
+
+	reader, err := zip.NewReader(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	require.NoError(t, err)
+
+	files := map[string]string{}
+	for _, f := range reader.File {
+		rc, err := f.Open()
+		require.NoError(t, err)
+		content, err := io.ReadAll(rc)
+		require.NoError(t, err)
+		rc.Close()
+		files[f.Name] = string(content)
+	}
+
+	assert.Contains(t, files, "profile.json")
+	assert.Contains(t, files, "labels.json")
+
+	var books []book_model.BookResponse
+	require.NoError(t, json.Unmarshal([]byte(files["books.json"]), &books))
+	assert.Len(t, books, 2)
+
+	var notes []note_model.NoteResponse
+	require.NoError(t, json.Unmarshal([]byte(files["notes.json"]), &notes))
+	assert.Len(t, notes, 2)
+
+	markdown := files["markdown/my-journal-"+bookId.Hex()+".md"]
+	assert.Contains(t, markdown, "# My Journal")
+	assert.Contains(t, markdown, "Labels: work")
+	assert.Contains(t, markdown, "_Archived_")
+	assert.Contains(t, markdown, "_2024-05-01 15:30_")
+	assert.Contains(t, markdown, "first note")
+	assert.NotContains(t, markdown, "other note")
+
+	assert.Contains(t, files, "markdown/"+otherBookId.Hex()+".md")
+}
+
+func TestJSONArrayWriterEmpty(t *testing.T) {
+	var buf bytes.Buffer
+	array := newJSONArrayWriter(&buf)
+	require.NoError(t, array.Close())
+
+	var values []interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &values))
+	assert.Empty(t, values)
+}
@@ -1,10 +1,12 @@
 package handler
 
 import (
+	"net/url"
 	_ "noto/internal/common"
 	"noto/internal/services/labels/model"
 	"noto/internal/services/labels/service"
 	"noto/internal/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -12,10 +14,14 @@ import (
 type LabelHandler interface {
 	CreateLabel(c *fiber.Ctx) error
 	GetLabels(c *fiber.Ctx) error
+	UpdateLabel(c *fiber.Ctx) error
 	DeleteLabel(c *fiber.Ctx) error
 	AddBookLabel(c *fiber.Ctx) error
 	DeleteBookLabel(c *fiber.Ctx) error
 	GetBookByLabel(c *fiber.Ctx) error
+	AddNoteLabel(c *fiber.Ctx) error
+	DeleteNoteLabel(c *fiber.Ctx) error
+	GetNoteByLabel(c *fiber.Ctx) error
 }
 
 type LabelHandlerImpl struct {
@@ -38,6 +44,7 @@ func NewLabelHandler(labelService service.LabelService) LabelHandler {
 // @Success		201		{object}	model.LabelCreate
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/labels [post]
 func (s *LabelHandlerImpl) CreateLabel(c *fiber.Ctx) error {
@@ -54,6 +61,9 @@ func (s *LabelHandlerImpl) CreateLabel(c *fiber.Ctx) error {
 	label.UserId = userId
 	newLabel, err := s.labelService.CreateLabel(label)
 	if err != nil {
+		if isValidationError(err) {
+			return utils.ErrorValidation(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, "failed to create label: "+err.Error())
 	}
 
@@ -62,11 +72,13 @@ func (s *LabelHandlerImpl) CreateLabel(c *fiber.Ctx) error {
 
 // GetLabels
 // @Summary		Get all labels
-// @Description	Get all labels
+// @Description	Get all labels. With tree=true the labels are nested by the slashes in their names and returned
+// @Description	as []model.LabelTree.
 // @Tags		Labels
 // @Security 	BearerAuth
 // @Produce		json
 // @Param 		Authorization header string false "Bearer token"
+// @Param		tree	query		bool	false	"Return the labels as a tree"
 // @Success		200		{object}	[]model.LabelResponse
 // @Failure     401     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
@@ -77,6 +89,15 @@ func (s *LabelHandlerImpl) GetLabels(c *fiber.Ctx) error {
 		return utils.ErrorUnauthorized(c, err.Error())
 	}
 
+	if c.QueryBool("tree", false) {
+		tree, err := s.labelService.GetLabelTree(userId)
+		if err != nil {
+			return utils.ErrorInternalServer(c, err.Error())
+		}
+
+		return c.JSON(tree)
+	}
+
 	labels, err := s.labelService.GetLabels(userId)
 	if err != nil {
 		return utils.ErrorInternalServer(c, err.Error())
@@ -85,6 +106,68 @@ func (s *LabelHandlerImpl) GetLabels(c *fiber.Ctx) error {
 	return c.JSON(labels)
 }
 
+// UpdateLabel
+// @Summary		Update label
+// @Description	Rename a label or change its color and icon. Only the fields that are sent change; an empty
+// @Description	color or icon removes it. Renaming a label also renames the labels nested under it, so
+// @Description	renaming "work" to "job" turns "work/clients" into "job/clients".
+// @Tags		Labels
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		labelId path string true "Label ID"
+// @Param		label	body		model.LabelUpdateSwagger	true	"Label fields to update"
+// @Success		200		{object}	model.LabelResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/labels/{labelId} [patch]
+func (s *LabelHandlerImpl) UpdateLabel(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	labelId, err := utils.ToObjectID(c.Params("labelId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	label := new(model.LabelUpdate)
+	if err := c.BodyParser(label); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	updated, err := s.labelService.UpdateLabel(userId, labelId, label)
+	if err != nil {
+		switch {
+		case err.Error() == "label not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case err.Error() == "label already exists":
+			return utils.ErrorConflict(c, err.Error())
+		case isValidationError(err):
+			return utils.ErrorValidation(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to update label: "+err.Error())
+	}
+
+	return c.JSON(updated)
+}
+
+func isValidationError(err error) bool {
+	switch err.Error() {
+	case "nothing to update", "name cannot be empty", "label name cannot have empty parts",
+		"a label cannot be moved under itself", "color must look like #rrggbb":
+		return true
+	}
+
+	return strings.HasPrefix(err.Error(), "icon must be at most")
+}
+
 // DeleteLabel
 // @Summary		Delete label
 // @Description	Delete label
@@ -131,6 +214,7 @@ func (s *LabelHandlerImpl) DeleteLabel(c *fiber.Ctx) error {
 // @Success		201		{object}	model.AddBookLabelResponse
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId}/labels [post]
 func (s *LabelHandlerImpl) AddBookLabel(c *fiber.Ctx) error {
@@ -153,6 +237,12 @@ func (s *LabelHandlerImpl) AddBookLabel(c *fiber.Ctx) error {
 	label.BookId = bookId
 	added, err := s.labelService.AddBookLabel(label)
 	if err != nil {
+		switch err.Error() {
+		case "label_name is required", "label name cannot have empty parts":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "book not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, "failed to add book label: "+err.Error())
 	}
 
@@ -194,6 +284,10 @@ func (s *LabelHandlerImpl) DeleteBookLabel(c *fiber.Ctx) error {
 	label.BookId = bookId
 	deleted := s.labelService.DeleteBookLabel(label)
 	if deleted != nil {
+		switch deleted.Error() {
+		case "label_name is required", "label name cannot have empty parts":
+			return utils.ErrorBadRequest(c, deleted.Error())
+		}
 		return utils.ErrorInternalServer(c, "failed to delete book label: "+deleted.Error())
 	}
 
@@ -204,17 +298,21 @@ func (s *LabelHandlerImpl) DeleteBookLabel(c *fiber.Ctx) error {
 
 // GetBookByLabel
 // @Summary		Get book by label name
-// @Description	Get book by label name
+// @Description	Get book by label name, including books tagged with a label nested under it. Nested labels
+// @Description	are separated by slashes, which are sent as %2F in the path. Pages are numbered by default; sending a cursor parameter (empty for the
+// @Description	first page) switches to keyset pagination, which answers with model.CursorBookResponse and a next_cursor.
 // @Tags		Labels
 // @Security 	BearerAuth
 // @Produce		json
 // @Param 		Authorization header string false "Bearer token"
 // @Param		labelName path string true "Label Name"
+// @Param		cursor		query		string	false	"Cursor from next_cursor for keyset pagination"
 // @Param		page		query		int		false	"Page number for pagination"	minimum(1)
 // @Param		limit		query		int		false	"Number of items per page"	minimum(1)
 // @Success		200		{object} 	model.PaginatedBookResponse
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/labels/{labelName}/books [get]
 func (s *LabelHandlerImpl) GetBookByLabel(c *fiber.Ctx) error {
@@ -223,14 +321,30 @@ func (s *LabelHandlerImpl) GetBookByLabel(c *fiber.Ctx) error {
 		return utils.ErrorUnauthorized(c, err.Error())
 	}
 
-	labelName := c.Params("labelName")
-	if labelName == "" {
+	labelName, err := url.PathUnescape(c.Params("labelName"))
+	if err != nil || labelName == "" {
 		return utils.ErrorBadRequest(c, "label name required!")
 	}
 
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
 
+	if cursor, ok := utils.CursorQuery(c); ok {
+		if limit < 1 {
+			return utils.ErrorValidation(c, "limit must be at least 1")
+		}
+
+		books, err := s.labelService.GetBookByLabelByCursor(userId, labelName, cursor, limit)
+		if err != nil {
+			if err.Error() == "invalid cursor" {
+				return utils.ErrorValidation(c, err.Error())
+			}
+			return utils.ErrorInternalServer(c, err.Error())
+		}
+
+		return c.JSON(books)
+	}
+
 	books, err := s.labelService.GetBookByLabel(userId, labelName, page, limit)
 
 	if err != nil {
@@ -239,3 +353,174 @@ func (s *LabelHandlerImpl) GetBookByLabel(c *fiber.Ctx) error {
 
 	return c.JSON(books)
 }
+
+// AddNoteLabel
+// @Summary		Add label to note
+// @Description	Add label to note. The label is created when it does not exist yet.
+// @Tags		Labels
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		label	body		model.NoteLabelSwagger	true	"Label to add"
+// @Success		201		{object}	model.AddNoteLabelResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/labels [post]
+func (s *LabelHandlerImpl) AddNoteLabel(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	label := new(model.NoteLabel)
+	if err := c.BodyParser(label); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	label.UserId = userId
+	label.BookId = bookId
+	label.NoteId = noteId
+
+	added, err := s.labelService.AddNoteLabel(label)
+	if err != nil {
+		switch err.Error() {
+		case "label_name is required", "label name cannot have empty parts":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "note not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to add note label: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(added)
+}
+
+// DeleteNoteLabel
+// @Summary		Delete label from note
+// @Description	Delete label from note
+// @Tags		Labels
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		label	body		model.NoteLabelSwagger	true	"Label to delete"
+// @Success		200		{object} 	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/labels [delete]
+func (s *LabelHandlerImpl) DeleteNoteLabel(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	label := new(model.NoteLabel)
+	if err := c.BodyParser(label); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	label.UserId = userId
+	label.BookId = bookId
+	label.NoteId = noteId
+
+	if err := s.labelService.DeleteNoteLabel(label); err != nil {
+		switch err.Error() {
+		case "label_name is required", "label name cannot have empty parts":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "label not found", "note label not found", "note not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to delete note label: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "deleted",
+	})
+}
+
+// GetNoteByLabel
+// @Summary		Get notes by label name
+// @Description	Get the notes of every book that carry the label or a label nested under it, most recently
+// @Description	updated first. Pages are numbered
+// @Description	by default; sending a cursor parameter (empty for the first page) switches to keyset pagination,
+// @Description	which answers with model.CursorNoteResponse and a next_cursor.
+// @Tags		Labels
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		labelName path string true "Label Name"
+// @Param		cursor		query		string	false	"Cursor from next_cursor for keyset pagination"
+// @Param		page		query		int		false	"Page number for pagination"	minimum(1)
+// @Param		limit		query		int		false	"Number of items per page"	minimum(1)
+// @Success		200		{object} 	model.PaginatedNoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/labels/{labelName}/notes [get]
+func (s *LabelHandlerImpl) GetNoteByLabel(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	labelName, err := url.PathUnescape(c.Params("labelName"))
+	if err != nil || labelName == "" {
+		return utils.ErrorBadRequest(c, "label name required!")
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	if cursor, ok := utils.CursorQuery(c); ok {
+		if limit < 1 {
+			return utils.ErrorValidation(c, "limit must be at least 1")
+		}
+
+		notes, err := s.labelService.GetNoteByLabelByCursor(userId, labelName, cursor, limit)
+		if err != nil {
+			if err.Error() == "invalid cursor" {
+				return utils.ErrorValidation(c, err.Error())
+			}
+			return utils.ErrorInternalServer(c, err.Error())
+		}
+
+		return c.JSON(notes)
+	}
+
+	notes, err := s.labelService.GetNoteByLabel(userId, labelName, page, limit)
+	if err != nil {
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(notes)
+}
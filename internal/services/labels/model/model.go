@@ -7,28 +7,56 @@ import (
 )
 
 type LabelCreateSwagger struct {
-	Name string `json:"name" bson:"name"`
+	Name  string `json:"name" bson:"name"`
+	Color string `json:"color" bson:"color" example:"#ffcc00"`
+	Icon  string `json:"icon" bson:"icon" example:"star"`
 }
 
 type LabelCreate struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
 	UserId    primitive.ObjectID `json:"user_id" bson:"userId"`
 	Name      string             `json:"name" bson:"name"`
+	Color     string             `json:"color,omitempty" bson:"color,omitempty"`
+	Icon      string             `json:"icon,omitempty" bson:"icon,omitempty"`
 	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
 }
 
+// LabelUpdate only changes the fields that are sent. An empty color or icon
+// removes it.
 type LabelUpdate struct {
-	Name string `json:"name" bson:"name"`
+	Name  *string `json:"name" bson:"name"`
+	Color *string `json:"color" bson:"color"`
+	Icon  *string `json:"icon" bson:"icon"`
+}
+
+type LabelUpdateSwagger struct {
+	Name  string `json:"name" example:"work"`
+	Color string `json:"color" example:"#ffcc00"`
+	Icon  string `json:"icon" example:"briefcase"`
 }
 
 type LabelResponse struct {
 	ID        primitive.ObjectID `json:"id" bson:"_id"`
 	Name      string             `json:"name" bson:"name"`
+	Color     string             `json:"color,omitempty" bson:"color,omitempty"`
+	Icon      string             `json:"icon,omitempty" bson:"icon,omitempty"`
 	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
 }
 
+// LabelTree is a label with the labels nested under it. Labels are nested by
+// slashes in their name, so "work/clients" is a child of "work". A parent that
+// is only implied by the names of its children has no id.
+type LabelTree struct {
+	ID       *primitive.ObjectID `json:"id"`
+	Name     string              `json:"name" example:"clients"`
+	Path     string              `json:"path" example:"work/clients"`
+	Color    string              `json:"color,omitempty"`
+	Icon     string              `json:"icon,omitempty"`
+	Children []LabelTree         `json:"children"`
+}
+
 type BookLabelSwagger struct {
 	LabelName string `json:"label_name" bson:"labelName"`
 }
@@ -39,6 +67,23 @@ type BookLabel struct {
 	LabelName string             `json:"label_name" bson:"labelName"`
 }
 
+type NoteLabelSwagger struct {
+	LabelName string `json:"label_name" bson:"labelName"`
+}
+
+type NoteLabel struct {
+	UserId    primitive.ObjectID `json:"user_id" bson:"userId"`
+	BookId    primitive.ObjectID `json:"book_id" bson:"bookId"`
+	NoteId    primitive.ObjectID `json:"note_id" bson:"noteId"`
+	LabelName string             `json:"label_name" bson:"labelName"`
+}
+
+type AddNoteLabelResponse struct {
+	ID      primitive.ObjectID `json:"id" bson:"_id"`
+	LabelId primitive.ObjectID `json:"label_id" bson:"labelId"`
+	NoteId  primitive.ObjectID `json:"note_id" bson:"noteId"`
+}
+
 type AddBookLabelResponse struct {
 	ID      primitive.ObjectID `json:"id" bson:"_id"`
 	LabelId primitive.ObjectID `json:"label_id" bson:"labelId"`
@@ -46,8 +91,10 @@ type AddBookLabelResponse struct {
 }
 
 type Label struct {
-	ID   string `json:"id" bson:"_id"`
-	Name string `json:"name" bson:"name"`
+	ID    string `json:"id" bson:"_id"`
+	Name  string `json:"name" bson:"name"`
+	Color string `json:"color,omitempty" bson:"color,omitempty"`
+	Icon  string `json:"icon,omitempty" bson:"icon,omitempty"`
 }
 
 type BookResponse struct {
@@ -59,6 +106,18 @@ type BookResponse struct {
 	Labels     []Label   `json:"labels" bson:"labels"`
 }
 
+type NoteResponse struct {
+	ID        string             `json:"id" bson:"_id"`
+	BookId    primitive.ObjectID `json:"book_id" bson:"bookId"`
+	Text      string             `json:"text" bson:"text"`
+	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+	Version   int64              `json:"version" bson:"version"`
+	Pinned    bool               `json:"pinned" bson:"pinned"`
+	Color     string             `json:"color,omitempty" bson:"color,omitempty"`
+	Labels    []Label            `json:"labels" bson:"labels"`
+}
+
 type PaginationMetadata struct {
 	TotalData    int  `json:"totalData" bson:"totalData"`
 	TotalPage    int  `json:"totalPage" bson:"totalPage"`
@@ -71,3 +130,22 @@ type PaginatedBookResponse struct {
 	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
 	Data     []BookResponse     `json:"data" bson:"data"`
 }
+
+// CursorBookResponse is a page of books in keyset pagination. NextCursor is
+// null on the last page.
+type CursorBookResponse struct {
+	Data       []BookResponse `json:"data"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+type PaginatedNoteResponse struct {
+	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
+	Data     []NoteResponse     `json:"data" bson:"data"`
+}
+
+// CursorNoteResponse is a page of notes in keyset pagination. NextCursor is
+// null on the last page.
+type CursorNoteResponse struct {
+	Data       []NoteResponse `json:"data"`
+	NextCursor *string        `json:"next_cursor"`
+}
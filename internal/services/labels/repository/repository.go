@@ -5,54 +5,198 @@ import (
 	"errors"
 	"noto/internal/services/labels/model"
 	"noto/internal/utils"
+	"regexp"
+	"strings"
 	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/bson/primitive"
 	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
 )
 
 type LabelRepository interface {
 	CheckAndInsertLabel(label *model.LabelCreate) (*model.LabelCreate, error)
 	CreateLabel(label *model.LabelCreate) (*model.LabelCreate, error)
 	GetLabels(userId primitive.ObjectID) ([]model.LabelResponse, error)
+	UpdateLabel(userId primitive.ObjectID, labelId primitive.ObjectID, label *model.LabelUpdate) (*model.LabelResponse, error)
 	DeleteLabel(userId primitive.ObjectID, labelId primitive.ObjectID) error
 	AddBookLabel(book *model.BookLabel) (*model.AddBookLabelResponse, error)
 	DeleteBookLabel(book *model.BookLabel) error
 	GetBookByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBookByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorBookResponse, error)
+	AddNoteLabel(note *model.NoteLabel) (*model.AddNoteLabelResponse, error)
+	DeleteNoteLabel(note *model.NoteLabel) error
+	GetNoteByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNoteByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorNoteResponse, error)
+	EnsureIndexes() error
 }
 
 type LabelRepositoryImpl struct {
+	db          *mongo.Database
 	labels      *mongo.Collection
 	book_labels *mongo.Collection
+	note_labels *mongo.Collection
+	notes       *mongo.Collection
+	books       *mongo.Collection
 }
 
 func NewLabelRepository(db *mongo.Database) LabelRepository {
-	return &LabelRepositoryImpl{labels: db.Collection("labels"), book_labels: db.Collection("book_labels")}
+	return &LabelRepositoryImpl{
+		db:          db,
+		labels:      db.Collection("labels"),
+		book_labels: db.Collection("book_labels"),
+		note_labels: db.Collection("note_labels"),
+		notes:       db.Collection("notes"),
+		books:       db.Collection("books"),
+	}
 }
 
-func (r *LabelRepositoryImpl) CheckAndInsertLabel(label *model.LabelCreate) (*model.LabelCreate, error) {
-	filter := bson.M{"userId": label.UserId, "name": label.Name}
-	existingLabel := r.labels.FindOne(context.Background(), filter)
+// EnsureIndexes keeps label names unique per user and a label attached to a
+// book or note at most once. Duplicates written before the indexes existed
+// are merged first, since the indexes cannot be built while they remain.
+func (r *LabelRepositoryImpl) EnsureIndexes() error {
+	if err := r.mergeDuplicates(context.Background()); err != nil {
+		return err
+	}
 
-	if existingLabel.Err() != nil {
-		if existingLabel.Err() == mongo.ErrNoDocuments {
-			newLabel, err := r.labels.InsertOne(context.Background(), label)
-			if err != nil {
-				return nil, err
+	_, err := r.labels.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "userId", Value: 1}, {Key: "name", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.book_labels.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "bookId", Value: 1}, {Key: "labelId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.note_labels.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "noteId", Value: 1}, {Key: "labelId", Value: 1}},
+		Options: options.Index().SetUnique(true),
+	})
+
+	return err
+}
+
+// mergeDuplicates keeps the oldest of the labels sharing a user and name,
+// moves the links of the others over to it and then drops links that are
+// attached more than once.
+func (r *LabelRepositoryImpl) mergeDuplicates(ctx context.Context) error {
+	groups, err := duplicates(ctx, r.labels, "userId", "name")
+	if err != nil {
+		return err
+	}
+
+	for _, ids := range groups {
+		keep, extra := ids[0], ids[1:]
+		err := utils.WithTransaction(ctx, r.db, func(ctx mongo.SessionContext) error {
+			for _, links := range []*mongo.Collection{r.book_labels, r.note_labels} {
+				if _, err := links.UpdateMany(ctx, bson.M{"labelId": bson.M{"$in": extra}}, bson.M{"$set": bson.M{"labelId": keep}}); err != nil {
+					return err
+				}
 			}
-			label.ID = newLabel.InsertedID.(primitive.ObjectID)
-		} else {
-			return nil, existingLabel.Err()
+
+			_, err := r.labels.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": extra}})
+			return err
+		})
+		if err != nil {
+			return err
 		}
-	} else {
-		err := existingLabel.Decode(&label)
+	}
+
+	for links, owner := range map[*mongo.Collection]string{r.book_labels: "bookId", r.note_labels: "noteId"} {
+		groups, err := duplicates(ctx, links, owner, "labelId")
 		if err != nil {
-			return nil, err
+			return err
+		}
+
+		for _, ids := range groups {
+			if _, err := links.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": ids[1:]}}); err != nil {
+				return err
+			}
 		}
 	}
 
-	return label, nil
+	return nil
+}
+
+// duplicates returns the ids of the documents sharing the same values for
+// fields, oldest first, for every group of more than one document.
+func duplicates(ctx context.Context, collection *mongo.Collection, fields ...string) ([][]primitive.ObjectID, error) {
+	key := bson.M{}
+	for _, field := range fields {
+		key[field] = "$" + field
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$sort", Value: bson.D{{Key: "_id", Value: 1}}}},
+		{{Key: "$group", Value: bson.M{
+			"_id":   key,
+			"ids":   bson.M{"$push": "$_id"},
+			"count": bson.M{"$sum": 1},
+		}}},
+		{{Key: "$match", Value: bson.M{"count": bson.M{"$gt": 1}}}},
+	}
+
+	cursor, err := collection.Aggregate(ctx, pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return nil, err
+	}
+
+	var groups []struct {
+		IDs []primitive.ObjectID `bson:"ids"`
+	}
+	if err := cursor.All(ctx, &groups); err != nil {
+		return nil, err
+	}
+
+	ids := make([][]primitive.ObjectID, 0, len(groups))
+	for _, group := range groups {
+		ids = append(ids, group.IDs)
+	}
+
+	return ids, nil
+}
+
+// CheckAndInsertLabel returns the user's label with the given name, creating
+// it first when it does not exist yet.
+func (r *LabelRepositoryImpl) CheckAndInsertLabel(label *model.LabelCreate) (*model.LabelCreate, error) {
+	filter := bson.M{"userId": label.UserId, "name": label.Name}
+	insert := bson.M{"createdAt": label.CreatedAt, "updatedAt": label.UpdatedAt}
+	if label.Color != "" {
+		insert["color"] = label.Color
+	}
+	if label.Icon != "" {
+		insert["icon"] = label.Icon
+	}
+
+	var result model.LabelCreate
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err := upsert(func() error {
+		return r.labels.FindOneAndUpdate(context.Background(), filter, bson.M{"$setOnInsert": insert}, opts).Decode(&result)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// upsert runs write again when it lost a race against a concurrent upsert of
+// the same document, which the unique indexes report as a duplicate key.
+func upsert(write func() error) error {
+	err := write()
+	if mongo.IsDuplicateKeyError(err) {
+		err = write()
+	}
+
+	return err
 }
 
 func (r *LabelRepositoryImpl) CreateLabel(label *model.LabelCreate) (*model.LabelCreate, error) {
@@ -67,7 +211,26 @@ func (r *LabelRepositoryImpl) CreateLabel(label *model.LabelCreate) (*model.Labe
 	return result, nil
 }
 
+// checkBook fails with "book not found" unless the book belongs to the user
+// and is not in the trash.
+func (r *LabelRepositoryImpl) checkBook(book *model.BookLabel) error {
+	count, err := r.books.CountDocuments(context.Background(), bson.M{"_id": book.BookId, "userId": book.UserId, "deletedAt": nil})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.New("book not found")
+	}
+
+	return nil
+}
+
 func (r *LabelRepositoryImpl) AddBookLabel(book *model.BookLabel) (*model.AddBookLabelResponse, error) {
+	if err := r.checkBook(book); err != nil {
+		return nil, err
+	}
+
 	label := &model.LabelCreate{
 		UserId:    book.UserId,
 		Name:      book.LabelName,
@@ -80,18 +243,13 @@ func (r *LabelRepositoryImpl) AddBookLabel(book *model.BookLabel) (*model.AddBoo
 		return nil, err
 	}
 
-	bookLabel := bson.M{
-		"userId":  book.UserId,
-		"bookId":  book.BookId,
-		"labelId": result.ID,
-	}
-	bookLabelResult, err := r.book_labels.InsertOne(context.Background(), bookLabel)
-	if err != nil {
-		return nil, err
-	}
-
 	var newBookLabel model.AddBookLabelResponse
-	err = r.book_labels.FindOne(context.Background(), bson.M{"_id": bookLabelResult.InsertedID}).Decode(&newBookLabel)
+	filter := bson.M{"bookId": book.BookId, "labelId": result.ID}
+	update := bson.M{"$setOnInsert": bson.M{"userId": book.UserId}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = upsert(func() error {
+		return r.book_labels.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&newBookLabel)
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -143,26 +301,151 @@ func (r *LabelRepositoryImpl) GetLabels(userId primitive.ObjectID) ([]model.Labe
 	return labels, nil
 }
 
-func (r *LabelRepositoryImpl) DeleteLabel(userId primitive.ObjectID, labelId primitive.ObjectID) error {
+// UpdateLabel renames and restyles a label. Names stay unique per user, and
+// renaming a label also renames the labels nested under it.
+func (r *LabelRepositoryImpl) UpdateLabel(userId primitive.ObjectID, labelId primitive.ObjectID, label *model.LabelUpdate) (*model.LabelResponse, error) {
+	now := time.Now()
+	set := bson.M{"updatedAt": now}
+	unset := bson.M{}
+
+	for field, value := range map[string]*string{"color": label.Color, "icon": label.Icon} {
+		if value == nil {
+			continue
+		}
+		if *value == "" {
+			unset[field] = ""
+		} else {
+			set[field] = *value
+		}
+	}
+
+	update := bson.M{"$set": set}
+	if len(unset) > 0 {
+		update["$unset"] = unset
+	}
+
+	var updated model.LabelResponse
 	filter := bson.M{"_id": labelId, "userId": userId}
-	deleted, err := r.labels.DeleteOne(context.Background(), filter)
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		var current model.LabelResponse
+		if err := r.labels.FindOne(ctx, filter).Decode(&current); err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("label not found")
+			}
+			return err
+		}
+
+		if label.Name != nil && *label.Name != current.Name {
+			if err := r.renameChildren(ctx, userId, current.Name, *label.Name, now); err != nil {
+				return err
+			}
+			set["name"] = *label.Name
+		}
+
+		err := r.labels.FindOneAndUpdate(ctx, filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After)).Decode(&updated)
+		if mongo.IsDuplicateKeyError(err) {
+			return errors.New("label already exists")
+		}
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &updated, nil
+}
+
+// renameChildren moves the labels nested under oldName below newName. It
+// fails when newName, or any of the new nested names, is already in use.
+func (r *LabelRepositoryImpl) renameChildren(ctx context.Context, userId primitive.ObjectID, oldName string, newName string, now time.Time) error {
+	if strings.HasPrefix(newName, oldName+"/") {
+		return errors.New("a label cannot be moved under itself")
+	}
+
+	opts := options.Find().SetProjection(bson.M{"name": 1})
+	cursor, err := r.labels.Find(ctx, bson.M{"userId": userId, "name": childrenOf(oldName)}, opts)
 	if err != nil {
 		return err
 	}
 
-	if deleted.DeletedCount == 0 {
-		return errors.New("label not found or not deleted")
+	var children []model.LabelResponse
+	if err := cursor.All(ctx, &children); err != nil {
+		return err
 	}
 
-	return nil
+	names := []string{newName}
+	ids := []primitive.ObjectID{}
+	writes := []mongo.WriteModel{}
+	for _, child := range children {
+		name := newName + strings.TrimPrefix(child.Name, oldName)
+		names = append(names, name)
+		ids = append(ids, child.ID)
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": child.ID}).
+			SetUpdate(bson.M{"$set": bson.M{"name": name, "updatedAt": now}}))
+	}
+
+	taken, err := r.labels.CountDocuments(ctx, bson.M{"userId": userId, "name": bson.M{"$in": names}, "_id": bson.M{"$nin": ids}})
+	if err != nil {
+		return err
+	}
+	if taken > 0 {
+		return errors.New("label already exists")
+	}
+
+	if len(writes) == 0 {
+		return nil
+	}
+
+	_, err = r.labels.BulkWrite(ctx, writes)
+	if mongo.IsDuplicateKeyError(err) {
+		return errors.New("label already exists")
+	}
+	return err
 }
 
-func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedBookResponse, error) {
-	pipeline := mongo.Pipeline{
+// childrenOf matches the names of every label nested under name, at any depth.
+func childrenOf(name string) bson.M {
+	return bson.M{"$regex": "^" + regexp.QuoteMeta(name+"/")}
+}
+
+// withChildren matches the label called name and every label nested under it.
+func withChildren(name string) bson.A {
+	return bson.A{bson.M{"name": name}, bson.M{"name": childrenOf(name)}}
+}
+
+// DeleteLabel removes the label together with its links to books and notes.
+func (r *LabelRepositoryImpl) DeleteLabel(userId primitive.ObjectID, labelId primitive.ObjectID) error {
+	return utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		deleted, err := r.labels.DeleteOne(ctx, bson.M{"_id": labelId, "userId": userId})
+		if err != nil {
+			return err
+		}
+
+		if deleted.DeletedCount == 0 {
+			return errors.New("label not found or not deleted")
+		}
+
+		if _, err := r.book_labels.DeleteMany(ctx, bson.M{"labelId": labelId}); err != nil {
+			return err
+		}
+
+		_, err = r.note_labels.DeleteMany(ctx, bson.M{"labelId": labelId})
+		return err
+	})
+}
+
+// labelBooksSort lists the most recently updated books first.
+var labelBooksSort = bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}
+
+// labelBooksStages resolves a label name to the live books tagged with it or
+// with any label nested under it.
+func labelBooksStages(userId primitive.ObjectID, labelName string) mongo.Pipeline {
+	return mongo.Pipeline{
 		{{
 			Key: "$match", Value: bson.M{
 				"userId": userId,
-				"name":   labelName,
+				"$or":    withChildren(labelName),
 			},
 		}},
 		{{
@@ -200,6 +483,9 @@ func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelNam
 				"preserveNullAndEmptyArrays": false,
 			},
 		}},
+		{{
+			Key: "$match", Value: bson.M{"books.userId": userId, "books.deletedAt": nil},
+		}},
 		{{
 			Key: "$project", Value: bson.M{
 				"_id":        "$books._id",
@@ -218,9 +504,12 @@ func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelNam
 				"isArchived": bson.M{"$first": "$isArchived"},
 			},
 		}},
-		{{
-			Key: "$sort", Value: bson.M{"updatedAt": -1},
-		}},
+	}
+}
+
+// bookLabelsStages embeds the labels of each book.
+func bookLabelsStages() mongo.Pipeline {
+	return mongo.Pipeline{
 		{{
 			Key: "$lookup", Value: bson.M{
 				"from":         "book_labels",
@@ -245,12 +534,16 @@ func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelNam
 				"labels.updatedAt": 0,
 			},
 		}},
-		{{
-			Key: "$sort", Value: bson.M{"updatedAt": -1},
-		}},
-		{{Key: "$facet", Value: utils.PaginationAggregate(page, limit)}},
-		{{Key: "$unwind", Value: "$metadata"}},
 	}
+}
+
+func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedBookResponse, error) {
+	pipeline := labelBooksStages(userId, labelName)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: labelBooksSort}},
+		bson.D{{Key: "$facet", Value: utils.PaginationAggregateWith(page, limit, bookLabelsStages())}},
+		bson.D{{Key: "$unwind", Value: "$metadata"}},
+	)
 
 	cursor, err := r.labels.Aggregate(context.Background(), pipeline)
 	if err != nil {
@@ -271,3 +564,246 @@ func (r *LabelRepositoryImpl) GetBookByLabel(userId primitive.ObjectID, labelNam
 
 	return &books[0], err
 }
+
+// GetBookByLabelByCursor is the keyset paginated variant of GetBookByLabel.
+func (r *LabelRepositoryImpl) GetBookByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorBookResponse, error) {
+	keyset, err := utils.KeysetMatch(labelBooksSort, cursor, utils.KeysetDocument(r.books, bson.M{"userId": userId}))
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := labelBooksStages(userId, labelName)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$match", Value: keyset}},
+		bson.D{{Key: "$sort", Value: labelBooksSort}},
+		bson.D{{Key: "$limit", Value: limit + 1}},
+	)
+	pipeline = append(pipeline, bookLabelsStages()...)
+
+	results, err := r.labels.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.Raw
+	if err := results.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	page, next, err := utils.KeysetPage(labelBooksSort, documents, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	books := make([]model.BookResponse, len(page))
+	for i, document := range page {
+		if err := bson.Unmarshal(document, &books[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.CursorBookResponse{Data: books, NextCursor: next}, nil
+}
+
+// checkNote fails with "note not found" unless the note belongs to the book
+// and is not in the trash.
+func (r *LabelRepositoryImpl) checkNote(note *model.NoteLabel) error {
+	count, err := r.notes.CountDocuments(context.Background(), bson.M{"_id": note.NoteId, "userId": note.UserId, "bookId": note.BookId, "deletedAt": nil})
+	if err != nil {
+		return err
+	}
+
+	if count == 0 {
+		return errors.New("note not found")
+	}
+
+	return nil
+}
+
+// AddNoteLabel attaches a label to a note of the book, creating the label
+// when the user does not have it yet.
+func (r *LabelRepositoryImpl) AddNoteLabel(note *model.NoteLabel) (*model.AddNoteLabelResponse, error) {
+	if err := r.checkNote(note); err != nil {
+		return nil, err
+	}
+
+	label := &model.LabelCreate{
+		UserId:    note.UserId,
+		Name:      note.LabelName,
+		CreatedAt: time.Now(),
+		UpdatedAt: time.Now(),
+	}
+
+	result, err := r.CheckAndInsertLabel(label)
+	if err != nil {
+		return nil, err
+	}
+
+	var newNoteLabel model.AddNoteLabelResponse
+	filter := bson.M{"noteId": note.NoteId, "labelId": result.ID}
+	update := bson.M{"$setOnInsert": bson.M{"userId": note.UserId, "createdAt": time.Now()}}
+	opts := options.FindOneAndUpdate().SetUpsert(true).SetReturnDocument(options.After)
+	err = upsert(func() error {
+		return r.note_labels.FindOneAndUpdate(context.Background(), filter, update, opts).Decode(&newNoteLabel)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &newNoteLabel, nil
+}
+
+func (r *LabelRepositoryImpl) DeleteNoteLabel(note *model.NoteLabel) error {
+	if err := r.checkNote(note); err != nil {
+		return err
+	}
+
+	var label model.LabelResponse
+	err := r.labels.FindOne(context.Background(), bson.M{"userId": note.UserId, "name": note.LabelName}).Decode(&label)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return errors.New("label not found")
+		}
+		return err
+	}
+
+	deleted, err := r.note_labels.DeleteOne(context.Background(), bson.M{"noteId": note.NoteId, "labelId": label.ID, "userId": note.UserId})
+	if err != nil {
+		return err
+	}
+
+	if deleted.DeletedCount == 0 {
+		return errors.New("note label not found")
+	}
+
+	return nil
+}
+
+// labelNotesSort lists the most recently updated notes first.
+var labelNotesSort = bson.D{{Key: "updatedAt", Value: -1}, {Key: "_id", Value: -1}}
+
+// labelNotesStages resolves a label name to the live notes tagged with it or
+// with any label nested under it, leaving out notes of books in the trash.
+func labelNotesStages(userId primitive.ObjectID, labelName string) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{
+			Key: "$match", Value: bson.M{
+				"userId": userId,
+				"$or":    withChildren(labelName),
+			},
+		}},
+		{{
+			Key: "$lookup", Value: bson.M{
+				"from":         "note_labels",
+				"localField":   "_id",
+				"foreignField": "labelId",
+				"as":           "note_labels",
+			},
+		}},
+		{{
+			Key: "$unwind", Value: "$note_labels",
+		}},
+		{{
+			Key: "$lookup", Value: bson.M{
+				"from":         "notes",
+				"localField":   "note_labels.noteId",
+				"foreignField": "_id",
+				"as":           "note",
+			},
+		}},
+		{{
+			Key: "$unwind", Value: "$note",
+		}},
+		{{
+			Key: "$match", Value: bson.M{"note.deletedAt": nil},
+		}},
+		{{
+			Key: "$lookup", Value: bson.M{
+				"from":         "books",
+				"localField":   "note.bookId",
+				"foreignField": "_id",
+				"as":           "book",
+			},
+		}},
+		{{
+			Key: "$match", Value: bson.M{"book.deletedAt": nil},
+		}},
+		{{
+			Key: "$group", Value: bson.M{
+				"_id":  "$note._id",
+				"note": bson.M{"$first": "$note"},
+			},
+		}},
+		{{
+			Key: "$replaceRoot", Value: bson.M{"newRoot": "$note"},
+		}},
+	}
+}
+
+func (r *LabelRepositoryImpl) GetNoteByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedNoteResponse, error) {
+	pipeline := labelNotesStages(userId, labelName)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$sort", Value: labelNotesSort}},
+		bson.D{{Key: "$facet", Value: utils.PaginationAggregateWith(page, limit, utils.NoteLabelStages())}},
+		bson.D{{Key: "$unwind", Value: "$metadata"}},
+	)
+
+	cursor, err := r.labels.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []model.PaginatedNoteResponse
+	if err = cursor.All(context.Background(), &notes); err != nil {
+		return nil, err
+	}
+
+	if len(notes) == 0 {
+		return &model.PaginatedNoteResponse{
+			Data:     []model.NoteResponse{},
+			Metadata: model.PaginationMetadata{},
+		}, nil
+	}
+
+	return &notes[0], nil
+}
+
+// GetNoteByLabelByCursor is the keyset paginated variant of GetNoteByLabel.
+func (r *LabelRepositoryImpl) GetNoteByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorNoteResponse, error) {
+	keyset, err := utils.KeysetMatch(labelNotesSort, cursor, utils.KeysetDocument(r.notes, bson.M{"userId": userId}))
+	if err != nil {
+		return nil, err
+	}
+
+	pipeline := labelNotesStages(userId, labelName)
+	pipeline = append(pipeline,
+		bson.D{{Key: "$match", Value: keyset}},
+		bson.D{{Key: "$sort", Value: labelNotesSort}},
+		bson.D{{Key: "$limit", Value: limit + 1}},
+	)
+	pipeline = append(pipeline, utils.NoteLabelStages()...)
+
+	results, err := r.labels.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.Raw
+	if err := results.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	page, next, err := utils.KeysetPage(labelNotesSort, documents, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]model.NoteResponse, len(page))
+	for i, document := range page {
+		if err := bson.Unmarshal(document, &notes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.CursorNoteResponse{Data: notes, NextCursor: next}, nil
+}
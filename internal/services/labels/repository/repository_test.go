@@ -0,0 +1,141 @@
+package repository
+
+import (
+	"context"
+	"noto/internal/config"
+	"noto/internal/services/labels/model"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+var (
+	now    time.Time
+	userId primitive.ObjectID
+	bookId primitive.ObjectID
+	noteId primitive.ObjectID
+	repo   LabelRepository
+)
+
+func TestMain(m *testing.M) {
+	config.LoadConfig()
+	repo = NewLabelRepository(config.DB)
+	if err := repo.EnsureIndexes(); err != nil {
+		panic(err)
+	}
+
+	now = time.Now()
+	userId = primitive.NewObjectID()
+	bookId = primitive.NewObjectID()
+	noteId = primitive.NewObjectID()
+
+	_, err := config.DB.Collection("books").InsertOne(context.Background(), bson.M{
+		"_id":       bookId,
+		"userId":    userId,
+		"title":     "Test Book",
+		"createdAt": now,
+		"updatedAt": now,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	_, err = config.DB.Collection("notes").InsertOne(context.Background(), bson.M{
+		"_id":       noteId,
+		"userId":    userId,
+		"bookId":    bookId,
+		"text":      "Test Note",
+		"createdAt": now,
+		"updatedAt": now,
+	})
+	if err != nil {
+		panic(err)
+	}
+
+	m.Run()
+
+	cleanupDatabase()
+}
+
+func cleanupDatabase() {
+	for _, name := range []string{"books", "notes", "labels", "book_labels", "note_labels"} {
+		if _, err := config.DB.Collection(name).DeleteMany(context.Background(), bson.M{"userId": userId}); err != nil {
+			panic(err)
+		}
+	}
+}
+
+func countDocuments(t *testing.T, collection string, filter bson.M) int64 {
+	count, err := config.DB.Collection(collection).CountDocuments(context.Background(), filter)
+	require.NoError(t, err, "Failed to count "+collection)
+	return count
+}
+
+func TestAddBookLabelIdempotent(t *testing.T) {
+	bookLabel := &model.BookLabel{UserId: userId, BookId: bookId, LabelName: "idempotent"}
+
+	first, err := repo.AddBookLabel(bookLabel)
+	require.NoError(t, err, "Failed to add book label")
+
+	second, err := repo.AddBookLabel(bookLabel)
+	require.NoError(t, err, "Failed to add book label again")
+
+	assert.Equal(t, first, second, "Adding the same label twice should return the same link")
+	assert.Equal(t, int64(1), countDocuments(t, "labels", bson.M{"userId": userId, "name": "idempotent"}))
+	assert.Equal(t, int64(1), countDocuments(t, "book_labels", bson.M{"bookId": bookId, "labelId": first.LabelId}))
+}
+
+func TestCreateLabelIdempotent(t *testing.T) {
+	first, err := repo.CreateLabel(&model.LabelCreate{UserId: userId, Name: "created"})
+	require.NoError(t, err, "Failed to create label")
+
+	second, err := repo.CreateLabel(&model.LabelCreate{UserId: userId, Name: "created"})
+	require.NoError(t, err, "Failed to create label again")
+
+	assert.Equal(t, first.ID, second.ID, "Creating the same label twice should return the same label")
+	assert.Equal(t, int64(1), countDocuments(t, "labels", bson.M{"userId": userId, "name": "created"}))
+}
+
+func TestDeleteLabelCascade(t *testing.T) {
+	bookLabel, err := repo.AddBookLabel(&model.BookLabel{UserId: userId, BookId: bookId, LabelName: "cascade"})
+	require.NoError(t, err, "Failed to add book label")
+
+	noteLabel, err := repo.AddNoteLabel(&model.NoteLabel{UserId: userId, BookId: bookId, NoteId: noteId, LabelName: "cascade"})
+	require.NoError(t, err, "Failed to add note label")
+	require.Equal(t, bookLabel.LabelId, noteLabel.LabelId, "Book and note should share the label")
+
+	err = repo.DeleteLabel(userId, bookLabel.LabelId)
+	require.NoError(t, err, "Failed to delete label")
+
+	assert.Equal(t, int64(0), countDocuments(t, "labels", bson.M{"_id": bookLabel.LabelId}))
+	assert.Equal(t, int64(0), countDocuments(t, "book_labels", bson.M{"labelId": bookLabel.LabelId}))
+	assert.Equal(t, int64(0), countDocuments(t, "note_labels", bson.M{"labelId": bookLabel.LabelId}))
+}
+
+func TestDeleteLabelNotFound(t *testing.T) {
+	err := repo.DeleteLabel(userId, primitive.NewObjectID())
+
+	assert.EqualError(t, err, "label not found or not deleted")
+}
+
+func TestDeleteNoteLabelOtherBook(t *testing.T) {
+	_, err := repo.AddNoteLabel(&model.NoteLabel{UserId: userId, BookId: bookId, NoteId: noteId, LabelName: "other-book"})
+	require.NoError(t, err, "Failed to add note label")
+
+	err = repo.DeleteNoteLabel(&model.NoteLabel{UserId: userId, BookId: primitive.NewObjectID(), NoteId: noteId, LabelName: "other-book"})
+	assert.EqualError(t, err, "note not found")
+
+	err = repo.DeleteNoteLabel(&model.NoteLabel{UserId: userId, BookId: bookId, NoteId: noteId, LabelName: "other-book"})
+	assert.NoError(t, err, "Failed to delete note label")
+}
+
+func TestAddBookLabelOtherUser(t *testing.T) {
+	_, err := repo.AddBookLabel(&model.BookLabel{UserId: primitive.NewObjectID(), BookId: bookId, LabelName: "foreign"})
+
+	assert.EqualError(t, err, "book not found")
+	assert.Equal(t, int64(0), countDocuments(t, "labels", bson.M{"name": "foreign"}))
+}
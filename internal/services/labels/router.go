@@ -1,10 +1,13 @@
 package labels_router
 
 import (
+	"log"
 	"noto/internal/config"
+	"noto/internal/middleware"
 	"noto/internal/services/labels/handler"
 	"noto/internal/services/labels/repository"
 	"noto/internal/services/labels/service"
+	"noto/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
@@ -14,10 +17,21 @@ func LabelsRouter(router fiber.Router) {
 	var serv = service.NewLabelService(repo)
 	var hand = handler.NewLabelHandler(serv)
 
-	router.Post("/labels", hand.CreateLabel)
-	router.Get("/labels", hand.GetLabels)
-	router.Delete("/labels/:labelId", hand.DeleteLabel)
-	router.Post("/books/:bookId/labels", hand.AddBookLabel)
-	router.Delete("/books/:bookId/labels", hand.DeleteBookLabel)
-	router.Get("/labels/:labelName/books", hand.GetBookByLabel)
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create label indexes:", err)
+	}
+
+	var read = middleware.RequireScope(utils.ScopeLabelsRead)
+	var write = middleware.RequireScope(utils.ScopeLabelsWrite)
+
+	router.Post("/labels", write, hand.CreateLabel)
+	router.Get("/labels", read, hand.GetLabels)
+	router.Patch("/labels/:labelId", write, hand.UpdateLabel)
+	router.Delete("/labels/:labelId", write, hand.DeleteLabel)
+	router.Post("/books/:bookId/labels", write, hand.AddBookLabel)
+	router.Delete("/books/:bookId/labels", write, hand.DeleteBookLabel)
+	router.Get("/labels/:labelName/books", middleware.RequireScope(utils.ScopeLabelsRead, utils.ScopeBooksRead), hand.GetBookByLabel)
+	router.Post("/books/:bookId/notes/:noteId/labels", middleware.RequireScope(utils.ScopeLabelsWrite, utils.ScopeNotesWrite), hand.AddNoteLabel)
+	router.Delete("/books/:bookId/notes/:noteId/labels", middleware.RequireScope(utils.ScopeLabelsWrite, utils.ScopeNotesWrite), hand.DeleteNoteLabel)
+	router.Get("/labels/:labelName/notes", middleware.RequireScope(utils.ScopeLabelsRead, utils.ScopeNotesRead), hand.GetNoteByLabel)
 }
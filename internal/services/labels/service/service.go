@@ -1,8 +1,14 @@
 package service
 
 import (
+	"errors"
+	"fmt"
 	"noto/internal/services/labels/model"
 	"noto/internal/services/labels/repository"
+	"noto/internal/utils"
+	"sort"
+	"strings"
+	"unicode/utf8"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
@@ -10,10 +16,17 @@ import (
 type LabelService interface {
 	CreateLabel(label *model.LabelCreate) (*model.LabelCreate, error)
 	GetLabels(userId primitive.ObjectID) ([]model.LabelResponse, error)
+	GetLabelTree(userId primitive.ObjectID) ([]model.LabelTree, error)
+	UpdateLabel(userId primitive.ObjectID, labelId primitive.ObjectID, label *model.LabelUpdate) (*model.LabelResponse, error)
 	DeleteLabel(userId primitive.ObjectID, labelId primitive.ObjectID) error
 	AddBookLabel(book *model.BookLabel) (*model.AddBookLabelResponse, error)
 	DeleteBookLabel(book *model.BookLabel) error
 	GetBookByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedBookResponse, error)
+	GetBookByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorBookResponse, error)
+	AddNoteLabel(note *model.NoteLabel) (*model.AddNoteLabelResponse, error)
+	DeleteNoteLabel(note *model.NoteLabel) error
+	GetNoteByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNoteByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorNoteResponse, error)
 }
 
 type LabelServiceImpl struct {
@@ -24,26 +37,217 @@ func NewLabelService(labelRepo repository.LabelRepository) LabelService {
 	return &LabelServiceImpl{labelRepo: labelRepo}
 }
 
+const maxIconLength = 32
+
 func (r *LabelServiceImpl) CreateLabel(label *model.LabelCreate) (*model.LabelCreate, error) {
+	name, err := cleanName(label.Name)
+	if err != nil {
+		return nil, err
+	}
+	label.Name = name
+
+	if err := validateStyle(label.Color, label.Icon); err != nil {
+		return nil, err
+	}
+
 	return r.labelRepo.CreateLabel(label)
 }
 
+func (r *LabelServiceImpl) UpdateLabel(userId primitive.ObjectID, labelId primitive.ObjectID, label *model.LabelUpdate) (*model.LabelResponse, error) {
+	if label.Name == nil && label.Color == nil && label.Icon == nil {
+		return nil, errors.New("nothing to update")
+	}
+
+	if label.Name != nil {
+		name, err := cleanName(*label.Name)
+		if err != nil {
+			return nil, err
+		}
+		label.Name = &name
+	}
+
+	var color, icon string
+	if label.Color != nil {
+		color = *label.Color
+	}
+	if label.Icon != nil {
+		icon = *label.Icon
+	}
+	if err := validateStyle(color, icon); err != nil {
+		return nil, err
+	}
+
+	return r.labelRepo.UpdateLabel(userId, labelId, label)
+}
+
+// cleanName trims every part of a slash separated label name, so
+// " work / clients " becomes "work/clients".
+func cleanName(name string) (string, error) {
+	if strings.TrimSpace(name) == "" {
+		return "", errors.New("name cannot be empty")
+	}
+
+	parts := strings.Split(name, "/")
+	for i, part := range parts {
+		parts[i] = strings.TrimSpace(part)
+		if parts[i] == "" {
+			return "", errors.New("label name cannot have empty parts")
+		}
+	}
+
+	return strings.Join(parts, "/"), nil
+}
+
+// validateStyle accepts an empty color or icon, which means none.
+func validateStyle(color string, icon string) error {
+	if color != "" && !utils.IsHexColor(color) {
+		return errors.New("color must look like #rrggbb")
+	}
+
+	if utf8.RuneCountInString(icon) > maxIconLength {
+		return fmt.Errorf("icon must be at most %d characters", maxIconLength)
+	}
+
+	return nil
+}
+
 func (r *LabelServiceImpl) GetLabels(userId primitive.ObjectID) ([]model.LabelResponse, error) {
 	return r.labelRepo.GetLabels(userId)
 }
 
+func (r *LabelServiceImpl) GetLabelTree(userId primitive.ObjectID) ([]model.LabelTree, error) {
+	labels, err := r.labelRepo.GetLabels(userId)
+	if err != nil {
+		return nil, err
+	}
+
+	return labelTree(labels), nil
+}
+
+// labelTree nests the labels by the parts of their names, adding the parents
+// that only exist as part of a name. Siblings are sorted by name.
+func labelTree(labels []model.LabelResponse) []model.LabelTree {
+	type treeNode struct {
+		label    model.LabelTree
+		children []*treeNode
+	}
+
+	root := &treeNode{}
+	nodes := map[string]*treeNode{}
+
+	var node func(path string) *treeNode
+	node = func(path string) *treeNode {
+		if existing, ok := nodes[path]; ok {
+			return existing
+		}
+
+		parent, name := root, path
+		if i := strings.LastIndex(path, "/"); i >= 0 {
+			parent, name = node(path[:i]), path[i+1:]
+		}
+
+		nodes[path] = &treeNode{label: model.LabelTree{Name: name, Path: path}}
+		parent.children = append(parent.children, nodes[path])
+		return nodes[path]
+	}
+
+	for _, label := range labels {
+		tree := node(label.Name)
+		id := label.ID
+		tree.label.ID = &id
+		tree.label.Color = label.Color
+		tree.label.Icon = label.Icon
+	}
+
+	var build func(nodes []*treeNode) []model.LabelTree
+	build = func(nodes []*treeNode) []model.LabelTree {
+		sort.Slice(nodes, func(i, j int) bool {
+			return nodes[i].label.Name < nodes[j].label.Name
+		})
+
+		trees := make([]model.LabelTree, len(nodes))
+		for i, node := range nodes {
+			trees[i] = node.label
+			trees[i].Children = build(node.children)
+		}
+		return trees
+	}
+
+	return build(root.children)
+}
+
 func (r *LabelServiceImpl) DeleteLabel(userId primitive.ObjectID, labelId primitive.ObjectID) error {
 	return r.labelRepo.DeleteLabel(userId, labelId)
 }
 
 func (r *LabelServiceImpl) AddBookLabel(book *model.BookLabel) (*model.AddBookLabelResponse, error) {
+	if strings.TrimSpace(book.LabelName) == "" {
+		return nil, errors.New("label_name is required")
+	}
+
+	name, err := cleanName(book.LabelName)
+	if err != nil {
+		return nil, err
+	}
+	book.LabelName = name
+
 	return r.labelRepo.AddBookLabel(book)
 }
 
 func (r *LabelServiceImpl) DeleteBookLabel(book *model.BookLabel) error {
+	if strings.TrimSpace(book.LabelName) == "" {
+		return errors.New("label_name is required")
+	}
+
+	name, err := cleanName(book.LabelName)
+	if err != nil {
+		return err
+	}
+	book.LabelName = name
+
 	return r.labelRepo.DeleteBookLabel(book)
 }
 
 func (r *LabelServiceImpl) GetBookByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedBookResponse, error) {
 	return r.labelRepo.GetBookByLabel(userId, labelName, page, limit)
 }
+
+func (r *LabelServiceImpl) GetBookByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorBookResponse, error) {
+	return r.labelRepo.GetBookByLabelByCursor(userId, labelName, cursor, limit)
+}
+
+func (r *LabelServiceImpl) AddNoteLabel(note *model.NoteLabel) (*model.AddNoteLabelResponse, error) {
+	if strings.TrimSpace(note.LabelName) == "" {
+		return nil, errors.New("label_name is required")
+	}
+
+	name, err := cleanName(note.LabelName)
+	if err != nil {
+		return nil, err
+	}
+	note.LabelName = name
+
+	return r.labelRepo.AddNoteLabel(note)
+}
+
+func (r *LabelServiceImpl) DeleteNoteLabel(note *model.NoteLabel) error {
+	if strings.TrimSpace(note.LabelName) == "" {
+		return errors.New("label_name is required")
+	}
+
+	name, err := cleanName(note.LabelName)
+	if err != nil {
+		return err
+	}
+	note.LabelName = name
+
+	return r.labelRepo.DeleteNoteLabel(note)
+}
+
+func (r *LabelServiceImpl) GetNoteByLabel(userId primitive.ObjectID, labelName string, page int, limit int) (*model.PaginatedNoteResponse, error) {
+	return r.labelRepo.GetNoteByLabel(userId, labelName, page, limit)
+}
+
+func (r *LabelServiceImpl) GetNoteByLabelByCursor(userId primitive.ObjectID, labelName string, cursor string, limit int) (*model.CursorNoteResponse, error) {
+	return r.labelRepo.GetNoteByLabelByCursor(userId, labelName, cursor, limit)
+}
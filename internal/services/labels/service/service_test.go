@@ -0,0 +1,234 @@
+package service
+
+import (
+	"noto/internal/services/labels/model"
+	"noto/internal/services/labels/repository"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubLabelRepo struct {
+	repository.LabelRepository
+	update *model.LabelUpdate
+	note   *model.NoteLabel
+	book   *model.BookLabel
+}
+
+func (r *stubLabelRepo) DeleteBookLabel(book *model.BookLabel) error {
+	r.book = book
+	return nil
+}
+
+func (r *stubLabelRepo) DeleteNoteLabel(note *model.NoteLabel) error {
+	r.note = note
+	return nil
+}
+
+func (r *stubLabelRepo) AddNoteLabel(note *model.NoteLabel) (*model.AddNoteLabelResponse, error) {
+	r.note = note
+	return &model.AddNoteLabelResponse{NoteId: note.NoteId}, nil
+}
+
+func (r *stubLabelRepo) UpdateLabel(userId primitive.ObjectID, labelId primitive.ObjectID, label *model.LabelUpdate) (*model.LabelResponse, error) {
+	r.update = label
+	return &model.LabelResponse{ID: labelId}, nil
+}
+
+func TestUpdateLabel(t *testing.T) {
+	text := func(value string) *string { return &value }
+
+	testCases := []struct {
+		name          string
+		update        model.LabelUpdate
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Trims Name",
+			update:       model.LabelUpdate{Name: text("  work  ")},
+			expectedName: "work",
+		},
+		{
+			name:   "Clears Color",
+			update: model.LabelUpdate{Color: text("")},
+		},
+		{
+			name:          "Nothing To Update",
+			update:        model.LabelUpdate{},
+			expectedError: "nothing to update",
+		},
+		{
+			name:          "Empty Name",
+			update:        model.LabelUpdate{Name: text(" ")},
+			expectedError: "name cannot be empty",
+		},
+		{
+			name:          "Invalid Color",
+			update:        model.LabelUpdate{Color: text("red")},
+			expectedError: "color must look like #rrggbb",
+		},
+		{
+			name:          "Long Icon",
+			update:        model.LabelUpdate{Icon: text(strings.Repeat("x", maxIconLength+1))},
+			expectedError: "icon must be at most 32 characters",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubLabelRepo{}
+			serv := NewLabelService(repo)
+
+			_, err := serv.UpdateLabel(primitive.NewObjectID(), primitive.NewObjectID(), &testCase.update)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, repo.update)
+				return
+			}
+
+			require.NoError(t, err)
+			if testCase.expectedName != "" {
+				assert.Equal(t, testCase.expectedName, *repo.update.Name)
+			}
+		})
+	}
+}
+
+func TestAddNoteLabel(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labelName     string
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Trims Name",
+			labelName:    " todo ",
+			expectedName: "todo",
+		},
+		{
+			name:          "Missing Name",
+			labelName:     "  ",
+			expectedError: "label_name is required",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubLabelRepo{}
+			serv := NewLabelService(repo)
+
+			_, err := serv.AddNoteLabel(&model.NoteLabel{NoteId: primitive.NewObjectID(), LabelName: testCase.labelName})
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, repo.note)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedName, repo.note.LabelName)
+		})
+	}
+}
+
+func TestDeleteLabelLinks(t *testing.T) {
+	testCases := []struct {
+		name          string
+		labelName     string
+		expectedName  string
+		expectedError string
+	}{
+		{
+			name:         "Cleans Nested Name",
+			labelName:    " work / urgent ",
+			expectedName: "work/urgent",
+		},
+		{
+			name:          "Missing Name",
+			labelName:     "  ",
+			expectedError: "label_name is required",
+		},
+		{
+			name:          "Empty Part",
+			labelName:     "work//urgent",
+			expectedError: "label name cannot have empty parts",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubLabelRepo{}
+			serv := NewLabelService(repo)
+
+			bookErr := serv.DeleteBookLabel(&model.BookLabel{BookId: primitive.NewObjectID(), LabelName: testCase.labelName})
+			noteErr := serv.DeleteNoteLabel(&model.NoteLabel{NoteId: primitive.NewObjectID(), LabelName: testCase.labelName})
+			if testCase.expectedError != "" {
+				assert.EqualError(t, bookErr, testCase.expectedError)
+				assert.EqualError(t, noteErr, testCase.expectedError)
+				assert.Nil(t, repo.book)
+				assert.Nil(t, repo.note)
+				return
+			}
+
+			require.NoError(t, bookErr)
+			require.NoError(t, noteErr)
+			assert.Equal(t, testCase.expectedName, repo.book.LabelName)
+			assert.Equal(t, testCase.expectedName, repo.note.LabelName)
+		})
+	}
+}
+
+func TestCleanName(t *testing.T) {
+	testCases := []struct {
+		name          string
+		label         string
+		expected      string
+		expectedError string
+	}{
+		{name: "Flat", label: " work ", expected: "work"},
+		{name: "Nested", label: " work / clients /acme", expected: "work/clients/acme"},
+		{name: "Empty", label: "  ", expectedError: "name cannot be empty"},
+		{name: "Empty Part", label: "work//acme", expectedError: "label name cannot have empty parts"},
+		{name: "Trailing Slash", label: "work/", expectedError: "label name cannot have empty parts"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			name, err := cleanName(testCase.label)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, name)
+		})
+	}
+}
+
+func TestLabelTree(t *testing.T) {
+	work := primitive.NewObjectID()
+	acme := primitive.NewObjectID()
+	home := primitive.NewObjectID()
+
+	tree := labelTree([]model.LabelResponse{
+		{ID: acme, Name: "work/clients/acme", Color: "#ff0000"},
+		{ID: home, Name: "home"},
+		{ID: work, Name: "work"},
+	})
+
+	assert.Equal(t, []model.LabelTree{
+		{ID: &home, Name: "home", Path: "home", Children: []model.LabelTree{}},
+		{ID: &work, Name: "work", Path: "work", Children: []model.LabelTree{
+			{Name: "clients", Path: "work/clients", Children: []model.LabelTree{
+				{ID: &acme, Name: "acme", Path: "work/clients/acme", Color: "#ff0000", Children: []model.LabelTree{}},
+			}},
+		}},
+	}, tree)
+
+	assert.Equal(t, []model.LabelTree{}, labelTree(nil))
+}
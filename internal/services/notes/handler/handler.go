@@ -5,15 +5,28 @@ import (
 	"noto/internal/services/notes/model"
 	service "noto/internal/services/notes/service"
 	"noto/internal/utils"
+	"strings"
 
 	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
 type NoteHandler interface {
 	GetNotes(c *fiber.Ctx) error
+	GetNote(c *fiber.Ctx) error
 	CreateNote(c *fiber.Ctx) error
 	UpdateNote(c *fiber.Ctx) error
 	DeleteNote(c *fiber.Ctx) error
+	GetRevisions(c *fiber.Ctx) error
+	DiffRevisions(c *fiber.Ctx) error
+	RestoreRevision(c *fiber.Ctx) error
+	MoveNote(c *fiber.Ctx) error
+	CopyNote(c *fiber.Ctx) error
+	MoveNotes(c *fiber.Ctx) error
+	CopyNotes(c *fiber.Ctx) error
+	BatchNotes(c *fiber.Ctx) error
+	PinNote(c *fiber.Ctx) error
+	ReorderNote(c *fiber.Ctx) error
 }
 
 type NoteHandlerImpl struct {
@@ -26,17 +39,28 @@ func NewNoteHandler(noteService service.NoteService) NoteHandler {
 
 // GetNotes
 // @Summary		Get notes by book id
-// @Description	Get notes by book id
+// @Description	Get notes by book id, pinned notes first and then in manual order. Pages are numbered by default;
+// @Description	sending a cursor parameter (empty for the first page) switches to keyset pagination, which answers
+// @Description	with model.CursorNoteResponse and a next_cursor.
 // @Tags		Notes
 // @Security 	BearerAuth
 // @Produce		json
 // @Param 		Authorization header string false "Bearer token"
 // @Param		bookId path string true "Book ID"
+// @Param		sort		query		string	false	"Sort by created_at, updated_at, position or title, optionally followed by :asc or :desc"	example(updated_at:desc)
+// @Param		created_after	query	string	false	"Only notes created after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		created_before	query	string	false	"Only notes created before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		updated_after	query	string	false	"Only notes updated after this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		updated_before	query	string	false	"Only notes updated before this time (RFC 3339 or YYYY-MM-DD)"
+// @Param		prefix		query		string	false	"Only notes whose text starts with this text, ignoring case"
+// @Param		cursor		query		string	false	"Cursor from next_cursor for keyset pagination"
 // @Param		page		query		int		false	"Page number for pagination"	minimum(1)
 // @Param		limit		query		int		false	"Number of items per page"	minimum(1)
 // @Success		200		{object}	model.PaginatedNoteResponse
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     422     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId}/notes [get]
 func (s *NoteHandlerImpl) GetNotes(c *fiber.Ctx) error {
@@ -53,14 +77,85 @@ func (s *NoteHandlerImpl) GetNotes(c *fiber.Ctx) error {
 	page := c.QueryInt("page", 1)
 	limit := c.QueryInt("limit", 10)
 
-	notes, err := s.noteService.GetNotes(userId, bookId, page, limit)
+	query, err := utils.ParseListQuery(c, model.NoteSortFields)
 	if err != nil {
+		return utils.ErrorValidation(c, err.Error())
+	}
+
+	if cursor, ok := utils.CursorQuery(c); ok {
+		if limit < 1 {
+			return utils.ErrorValidation(c, "limit must be at least 1")
+		}
+
+		notes, err := s.noteService.GetNotesByCursor(userId, bookId, query, cursor, limit)
+		if err != nil {
+			switch err.Error() {
+			case "invalid cursor":
+				return utils.ErrorValidation(c, err.Error())
+			case "book not found":
+				return utils.ErrorNotFound(c, err.Error())
+			}
+			return utils.ErrorInternalServer(c, err.Error())
+		}
+
+		return c.JSON(notes)
+	}
+
+	notes, err := s.noteService.GetNotes(userId, bookId, query, page, limit)
+	if err != nil {
+		if err.Error() == "book not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, err.Error())
 	}
 
 	return c.JSON(notes)
 }
 
+// GetNote
+// @Summary		Get note by id
+// @Description	Get note by id
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Success		200		{object}	model.NoteResponse
+// @Header		200		{string}	ETag	"Current note version"
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId} [get]
+func (s *NoteHandlerImpl) GetNote(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	note, err := s.noteService.GetNote(userId, bookId, noteId)
+	if err != nil {
+		if err.Error() == "note not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	utils.SetETag(c, note.Version)
+	return c.JSON(note)
+}
+
 // CreateNoe
 // @Summary		Create a new note
 // @Description	Create a new note
@@ -74,6 +169,7 @@ func (s *NoteHandlerImpl) GetNotes(c *fiber.Ctx) error {
 // @Success		201		{object}	model.NoteCreate
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId}/notes [post]
 func (s *NoteHandlerImpl) CreateNote(c *fiber.Ctx) error {
@@ -96,9 +192,16 @@ func (s *NoteHandlerImpl) CreateNote(c *fiber.Ctx) error {
 	note.BookId = bookId
 	newNote, err := s.noteService.CreateNote(note)
 	if err != nil {
+		switch err.Error() {
+		case "color must look like #rrggbb":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "book not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, "failed to create note: "+err.Error())
 	}
 
+	utils.SetETag(c, newNote.Version)
 	return c.Status(fiber.StatusCreated).JSON(newNote)
 }
 
@@ -112,10 +215,14 @@ func (s *NoteHandlerImpl) CreateNote(c *fiber.Ctx) error {
 // @Param 		Authorization header string false "Bearer token"
 // @Param		bookId path string true "Book ID"
 // @Param		noteId path string true "Note ID"
+// @Param 		If-Match header string false "Expected note version (ETag)"
 // @Param		book	body		model.NoteUpdateSwagger	true	"Note to update"
 // @Success		200		{object}	model.NoteResponse
+// @Header		200		{string}	ETag	"New note version"
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ConflictResponse{current=model.NoteResponse}
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId}/notes/{noteId} [patch]
 func (s *NoteHandlerImpl) UpdateNote(c *fiber.Ctx) error {
@@ -134,6 +241,11 @@ func (s *NoteHandlerImpl) UpdateNote(c *fiber.Ctx) error {
 		return utils.ErrorBadRequest(c, err.Error())
 	}
 
+	version, err := utils.IfMatchVersion(c)
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
 	note := new(model.NoteUpdate)
 	if err := c.BodyParser(note); err != nil {
 		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
@@ -142,17 +254,30 @@ func (s *NoteHandlerImpl) UpdateNote(c *fiber.Ctx) error {
 	note.ID = noteId
 	note.UserId = userId
 	note.BookId = bookId
+	note.Version = version
 	updatedNote, err := s.noteService.UpdateNote(note)
 	if err != nil {
+		switch err.Error() {
+		case "note not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case "version conflict":
+			current, err := s.noteService.GetNote(userId, bookId, noteId)
+			if err != nil {
+				return utils.ErrorInternalServer(c, "failed to update note: "+err.Error())
+			}
+			utils.SetETag(c, current.Version)
+			return utils.ErrorConflictWithCurrent(c, "version conflict", current)
+		}
 		return utils.ErrorInternalServer(c, "failed to update note: "+err.Error())
 	}
 
+	utils.SetETag(c, updatedNote.Version)
 	return c.JSON(updatedNote)
 }
 
 // DeleteNote
 // @Summary		Delete note
-// @Description	Deelete note
+// @Description	Move note to the trash
 // @Tags		Notes
 // @Security 	BearerAuth
 // @Produce		json
@@ -162,6 +287,7 @@ func (s *NoteHandlerImpl) UpdateNote(c *fiber.Ctx) error {
 // @Success		200		{object} 	interface{}
 // @Failure     400    	{object}   	common.ErrorResponse
 // @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
 // @Failure     500     {object}    common.ErrorResponse
 // @Router		/api/books/{bookId}/notes/{noteId} [delete]
 func (s *NoteHandlerImpl) DeleteNote(c *fiber.Ctx) error {
@@ -181,6 +307,9 @@ func (s *NoteHandlerImpl) DeleteNote(c *fiber.Ctx) error {
 	}
 
 	if err := s.noteService.DeleteNote(userId, bookId, noteId); err != nil {
+		if err.Error() == "note not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
 		return utils.ErrorInternalServer(c, "failed to delete note: "+err.Error())
 	}
 
@@ -188,3 +317,512 @@ func (s *NoteHandlerImpl) DeleteNote(c *fiber.Ctx) error {
 		"success": "note deleted",
 	})
 }
+
+// GetRevisions
+// @Summary		Get note revisions
+// @Description	Get previous versions of a note, newest first
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Success		200		{array}		model.NoteRevision
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/revisions [get]
+func (s *NoteHandlerImpl) GetRevisions(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	revisions, err := s.noteService.GetRevisions(userId, bookId, noteId)
+	if err != nil {
+		if err.Error() == "note not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(revisions)
+}
+
+// DiffRevisions
+// @Summary		Diff note revisions
+// @Description	Get a unified diff between two revisions of a note. Use "current" for the current text.
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		from	query	string	true	"Revision ID or current"
+// @Param		to		query	string	false	"Revision ID or current, defaults to current"
+// @Success		200		{object}	model.NoteRevisionDiff
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/revisions/diff [get]
+func (s *NoteHandlerImpl) DiffRevisions(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	diff, err := s.noteService.DiffRevisions(userId, bookId, noteId, c.Query("from"), c.Query("to"))
+	if err != nil {
+		switch err.Error() {
+		case "from is required", "invalid revision id":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "note not found", "revision not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(diff)
+}
+
+// RestoreRevision
+// @Summary		Restore note revision
+// @Description	Replace the text of a note with a previous revision. The replaced text is kept as a new revision.
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		revisionId path string true "Revision ID"
+// @Success		200		{object}	model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/revisions/{revisionId}/restore [post]
+func (s *NoteHandlerImpl) RestoreRevision(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	revisionId, err := utils.ToObjectID(c.Params("revisionId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	note, err := s.noteService.RestoreRevision(userId, bookId, noteId, revisionId)
+	if err != nil {
+		if err.Error() == "note not found" || err.Error() == "revision not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to restore revision: "+err.Error())
+	}
+
+	utils.SetETag(c, note.Version)
+	return c.JSON(note)
+}
+
+// MoveNote
+// @Summary		Move note
+// @Description	Move a note into another book of the user
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		target	body		model.NoteTransferSwagger	true	"Target book"
+// @Success		200		{object}	model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/move [post]
+func (s *NoteHandlerImpl) MoveNote(c *fiber.Ctx) error {
+	return s.transferNote(c, "failed to move note: ", s.noteService.MoveNotes)
+}
+
+// CopyNote
+// @Summary		Copy note
+// @Description	Copy a note into another book of the user, or into the same book
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		target	body		model.NoteTransferSwagger	true	"Target book"
+// @Success		201		{object}	model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/copy [post]
+func (s *NoteHandlerImpl) CopyNote(c *fiber.Ctx) error {
+	c.Status(fiber.StatusCreated)
+	return s.transferNote(c, "failed to copy note: ", s.noteService.CopyNotes)
+}
+
+// MoveNotes
+// @Summary		Move notes
+// @Description	Move several notes of a book into another book. Nothing is moved when one of the notes does not exist.
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		target	body		model.NoteBulkTransferSwagger	true	"Notes and target book"
+// @Success		200		{array}		model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/move [post]
+func (s *NoteHandlerImpl) MoveNotes(c *fiber.Ctx) error {
+	return s.transferNotes(c, "failed to move notes: ", s.noteService.MoveNotes)
+}
+
+// CopyNotes
+// @Summary		Copy notes
+// @Description	Copy several notes of a book into another book. Nothing is copied when one of the notes does not exist.
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		target	body		model.NoteBulkTransferSwagger	true	"Notes and target book"
+// @Success		201		{array}		model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/copy [post]
+func (s *NoteHandlerImpl) CopyNotes(c *fiber.Ctx) error {
+	c.Status(fiber.StatusCreated)
+	return s.transferNotes(c, "failed to copy notes: ", s.noteService.CopyNotes)
+}
+
+// BatchNotes
+// @Summary		Run note operations in bulk
+// @Description	Apply delete, move, pin, color, label and unlabel operations to many notes of a book in one transaction.
+// @Description	Each note gets its own result, so notes that do not exist are reported without failing the rest.
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		batch	body		model.NoteBatch	true	"Operations to run"
+// @Success		200		{object}	model.NoteBatchResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/batch [post]
+func (s *NoteHandlerImpl) BatchNotes(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	batch := new(model.NoteBatch)
+	if err := c.BodyParser(batch); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	for _, operation := range batch.Operations {
+		if operation.Op == model.BatchMove && !utils.CanAccessBook(c, operation.BookId) {
+			return utils.ErrorForbidden(c, "token is not allowed to access this book")
+		}
+	}
+
+	results, err := s.noteService.BatchNotes(userId, bookId, batch)
+	if err != nil {
+		switch {
+		case err.Error() == "book not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case err.Error() == "operations is required",
+			strings.HasPrefix(err.Error(), "invalid operation"),
+			strings.HasPrefix(err.Error(), "at most"):
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to run batch: "+err.Error())
+	}
+
+	return c.JSON(results)
+}
+
+// PinNote
+// @Summary		Pin or unpin note
+// @Description	Pinned notes are listed before all other notes of the book
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		pin		body		model.NotePin	true	"Pinned state"
+// @Success		200		{object}	model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/pin [patch]
+func (s *NoteHandlerImpl) PinNote(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	pin := new(model.NotePin)
+	if err := c.BodyParser(pin); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	note, err := s.noteService.SetPinned(userId, bookId, noteId, pin.Pinned)
+	if err != nil {
+		if err.Error() == "note not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to pin note: "+err.Error())
+	}
+
+	utils.SetETag(c, note.Version)
+	return c.JSON(note)
+}
+
+// ReorderNote
+// @Summary		Reorder note
+// @Description	Move a note between two other notes of the book, as shown in the note list
+// @Tags		Notes
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		bookId path string true "Book ID"
+// @Param		noteId path string true "Note ID"
+// @Param		neighbours	body	model.NoteReorderSwagger	true	"Notes around the new place"
+// @Success		200		{object}	model.NoteResponse
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/books/{bookId}/notes/{noteId}/reorder [post]
+func (s *NoteHandlerImpl) ReorderNote(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	body := new(model.NoteReorderSwagger)
+	if err := c.BodyParser(body); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	reorder := &model.NoteReorder{ID: noteId, UserId: userId, BookId: bookId}
+	if body.AfterId != "" {
+		afterId, err := utils.ToObjectID(body.AfterId)
+		if err != nil {
+			return utils.ErrorBadRequest(c, "invalid after_id")
+		}
+		reorder.AfterId = &afterId
+	}
+	if body.BeforeId != "" {
+		beforeId, err := utils.ToObjectID(body.BeforeId)
+		if err != nil {
+			return utils.ErrorBadRequest(c, "invalid before_id")
+		}
+		reorder.BeforeId = &beforeId
+	}
+
+	note, err := s.noteService.ReorderNote(reorder)
+	if err != nil {
+		switch err.Error() {
+		case "note not found", "book not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case "after_id or before_id is required", "a note cannot be placed next to itself",
+			"after_id and before_id must differ", "after_id must come before before_id":
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to reorder note: "+err.Error())
+	}
+
+	utils.SetETag(c, note.Version)
+	return c.JSON(note)
+}
+
+type transferFunc func(transfer *model.NoteTransfer) ([]model.NoteResponse, error)
+
+func (s *NoteHandlerImpl) transferNote(c *fiber.Ctx, prefix string, transfer transferFunc) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	body := new(model.NoteTransferSwagger)
+	if err := c.BodyParser(body); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	targetId, err := utils.ToObjectID(body.BookId)
+	if err != nil {
+		return utils.ErrorBadRequest(c, "invalid book_id")
+	}
+
+	if !utils.CanAccessBook(c, targetId.Hex()) {
+		return utils.ErrorForbidden(c, "token is not allowed to access this book")
+	}
+
+	notes, err := transfer(&model.NoteTransfer{
+		UserId:     userId,
+		FromBookId: bookId,
+		NoteIds:    []primitive.ObjectID{noteId},
+		BookId:     targetId,
+	})
+	if err != nil {
+		return transferError(c, prefix, err)
+	}
+
+	return c.JSON(notes[0])
+}
+
+func (s *NoteHandlerImpl) transferNotes(c *fiber.Ctx, prefix string, transfer transferFunc) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	body := new(model.NoteBulkTransferSwagger)
+	if err := c.BodyParser(body); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	noteIds := make([]primitive.ObjectID, 0, len(body.NoteIds))
+	for _, id := range body.NoteIds {
+		noteId, err := utils.ToObjectID(id)
+		if err != nil {
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		noteIds = append(noteIds, noteId)
+	}
+
+	targetId, err := utils.ToObjectID(body.BookId)
+	if err != nil {
+		return utils.ErrorBadRequest(c, "invalid book_id")
+	}
+
+	if !utils.CanAccessBook(c, targetId.Hex()) {
+		return utils.ErrorForbidden(c, "token is not allowed to access this book")
+	}
+
+	notes, err := transfer(&model.NoteTransfer{
+		UserId:     userId,
+		FromBookId: bookId,
+		NoteIds:    noteIds,
+		BookId:     targetId,
+	})
+	if err != nil {
+		return transferError(c, prefix, err)
+	}
+
+	return c.JSON(notes)
+}
+
+func transferError(c *fiber.Ctx, prefix string, err error) error {
+	switch err.Error() {
+	case "book not found", "target book not found", "note not found":
+		return utils.ErrorNotFound(c, err.Error())
+	case "book_id is required", "note_ids is required", "notes are already in this book":
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if strings.HasPrefix(err.Error(), "at most") {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	return utils.ErrorInternalServer(c, prefix+err.Error())
+}
@@ -0,0 +1,82 @@
+package handler
+
+import (
+	"errors"
+	"net/http/httptest"
+	model "noto/internal/services/notes/model"
+	"noto/internal/services/notes/service"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubNoteService struct {
+	service.NoteService
+	note *model.NoteResponse
+	err  error
+}
+
+func (s *stubNoteService) GetNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) (*model.NoteResponse, error) {
+	return s.note, s.err
+}
+
+func TestGetNote(t *testing.T) {
+	userId := primitive.NewObjectID()
+	bookId := primitive.NewObjectID().Hex()
+	noteId := primitive.NewObjectID().Hex()
+
+	testCases := []struct {
+		name           string
+		noteId         string
+		service        *stubNoteService
+		expectedStatus int
+		expectedETag   string
+	}{
+		{
+			name:           "Found",
+			noteId:         noteId,
+			service:        &stubNoteService{note: &model.NoteResponse{ID: noteId, Version: 3}},
+			expectedStatus: fiber.StatusOK,
+			expectedETag:   `"3"`,
+		},
+		{
+			name:           "Not Found",
+			noteId:         noteId,
+			service:        &stubNoteService{err: errors.New("note not found")},
+			expectedStatus: fiber.StatusNotFound,
+		},
+		{
+			name:           "Invalid Id",
+			noteId:         "invalid",
+			service:        &stubNoteService{},
+			expectedStatus: fiber.StatusBadRequest,
+		},
+		{
+			name:           "Internal Error",
+			noteId:         noteId,
+			service:        &stubNoteService{err: errors.New("connection lost")},
+			expectedStatus: fiber.StatusInternalServerError,
+		},
+	}
+
+	for _, tc := range testCases {
+		t.Run(tc.name, func(t *testing.T) {
+			app := fiber.New()
+			app.Use(func(c *fiber.Ctx) error {
+				c.Locals("userID", userId.Hex())
+				return c.Next()
+			})
+			app.Get("/books/:bookId/notes/:noteId", NewNoteHandler(tc.service).GetNote)
+
+			resp, err := app.Test(httptest.NewRequest("GET", "/books/"+bookId+"/notes/"+tc.noteId, nil))
+			require.NoError(t, err)
+			defer resp.Body.Close()
+
+			assert.Equal(t, tc.expectedStatus, resp.StatusCode)
+			assert.Equal(t, tc.expectedETag, resp.Header.Get(fiber.HeaderETag))
+		})
+	}
+}
@@ -7,7 +7,9 @@ import (
 )
 
 type NoteCreateSwagger struct {
-	Text string `json:"text" bson:"text"`
+	Text   string `json:"text" bson:"text"`
+	Pinned bool   `json:"pinned" bson:"pinned"`
+	Color  string `json:"color,omitempty" bson:"color,omitempty" example:"#ffcc00"`
 }
 
 type NoteCreate struct {
@@ -17,6 +19,10 @@ type NoteCreate struct {
 	Text      string             `json:"text" bson:"text"`
 	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+	Version   int64              `json:"version" bson:"version"`
+	Pinned    bool               `json:"pinned" bson:"pinned"`
+	Color     string             `json:"color,omitempty" bson:"color,omitempty"`
+	Position  string             `json:"position" bson:"position,omitempty"`
 }
 
 type NoteUpdateSwagger struct {
@@ -28,6 +34,99 @@ type NoteUpdate struct {
 	UserId primitive.ObjectID `json:"user_id" bson:"userId"`
 	BookId primitive.ObjectID `json:"book_id" bson:"bookId"`
 	Text   string             `json:"text" bson:"text"`
+	// Version is the expected current version taken from If-Match, nil to skip the check.
+	Version *int64 `json:"-" bson:"-"`
+}
+
+type NotePin struct {
+	Pinned bool `json:"pinned"`
+}
+
+// NoteReorderSwagger places a note between two neighbours as shown in the
+// list: after_id is the note above it and before_id the note below it. One of
+// them is enough, the other side is taken from the current order.
+type NoteReorderSwagger struct {
+	AfterId  string `json:"after_id,omitempty" example:"665f1f77bcf86cd799439011"`
+	BeforeId string `json:"before_id,omitempty" example:"665f1f77bcf86cd799439012"`
+}
+
+type NoteReorder struct {
+	ID       primitive.ObjectID
+	UserId   primitive.ObjectID
+	BookId   primitive.ObjectID
+	AfterId  *primitive.ObjectID
+	BeforeId *primitive.ObjectID
+}
+
+type NoteTransferSwagger struct {
+	BookId string `json:"book_id" example:"665f1f77bcf86cd799439011"`
+}
+
+type NoteBulkTransferSwagger struct {
+	NoteIds []string `json:"note_ids" example:"665f1f77bcf86cd799439012"`
+	BookId  string   `json:"book_id" example:"665f1f77bcf86cd799439011"`
+}
+
+// NoteTransfer moves or copies notes of one book into another book.
+type NoteTransfer struct {
+	UserId     primitive.ObjectID   `json:"-"`
+	FromBookId primitive.ObjectID   `json:"-"`
+	NoteIds    []primitive.ObjectID `json:"note_ids"`
+	BookId     primitive.ObjectID   `json:"book_id"`
+}
+
+const (
+	BatchDelete  = "delete"
+	BatchMove    = "move"
+	BatchPin     = "pin"
+	BatchColor   = "color"
+	BatchLabel   = "label"
+	BatchUnlabel = "unlabel"
+)
+
+// NoteBatchOperation applies one operation to several notes. Only the fields
+// of the chosen op are read: book_id for move, pinned for pin (true when
+// omitted), color for color (empty clears it) and label_id for label/unlabel.
+type NoteBatchOperation struct {
+	Op      string   `json:"op" enums:"delete,move,pin,color,label,unlabel"`
+	NoteIds []string `json:"note_ids"`
+	BookId  string   `json:"book_id,omitempty"`
+	Pinned  *bool    `json:"pinned,omitempty"`
+	Color   *string  `json:"color,omitempty" example:"#ffcc00"`
+	LabelId string   `json:"label_id,omitempty"`
+}
+
+type NoteBatch struct {
+	Operations []NoteBatchOperation `json:"operations"`
+}
+
+// NoteBatchItem is a single operation on a single note, as executed by the
+// repository.
+type NoteBatchItem struct {
+	Op      string
+	NoteId  primitive.ObjectID
+	BookId  primitive.ObjectID
+	Pinned  bool
+	Color   string
+	LabelId primitive.ObjectID
+}
+
+type NoteBatchResult struct {
+	Op      string `json:"op"`
+	NoteId  string `json:"note_id"`
+	Success bool   `json:"success"`
+	Error   string `json:"error,omitempty"`
+}
+
+type NoteBatchResponse struct {
+	Results []NoteBatchResult `json:"results"`
+}
+
+type Label struct {
+	ID    string `json:"id" bson:"_id"`
+	Name  string `json:"name" bson:"name"`
+	Color string `json:"color,omitempty" bson:"color,omitempty"`
+	Icon  string `json:"icon,omitempty" bson:"icon,omitempty"`
 }
 
 type NoteResponse struct {
@@ -36,6 +135,12 @@ type NoteResponse struct {
 	Text      string             `json:"text" bson:"text"`
 	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
 	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+	DeletedAt *time.Time         `json:"deleted_at,omitempty" bson:"deletedAt,omitempty"`
+	Version   int64              `json:"version" bson:"version"`
+	Pinned    bool               `json:"pinned" bson:"pinned"`
+	Color     string             `json:"color,omitempty" bson:"color,omitempty"`
+	Position  string             `json:"position,omitempty" bson:"position,omitempty"`
+	Labels    []Label            `json:"labels" bson:"labels"`
 }
 
 type PaginationMetadata struct {
@@ -46,7 +151,39 @@ type PaginationMetadata struct {
 	NextPage     *int `json:"nextPage" bson:"nextPage"`
 }
 
+// NoteSortFields are the values accepted by the sort parameter of note lists.
+// Pinned notes stay on top whatever the sort.
+var NoteSortFields = map[string]string{
+	"created_at": "createdAt",
+	"updated_at": "updatedAt",
+	"position":   "position",
+	"title":      "text",
+}
+
 type PaginatedNoteResponse struct {
 	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
 	Data     []NoteResponse     `json:"data" bson:"data"`
 }
+
+// CursorNoteResponse is a page of notes in keyset pagination. NextCursor is
+// null on the last page.
+type CursorNoteResponse struct {
+	Data       []NoteResponse `json:"data"`
+	NextCursor *string        `json:"next_cursor"`
+}
+
+type NoteRevision struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	NoteId    primitive.ObjectID `json:"note_id" bson:"noteId"`
+	UserId    primitive.ObjectID `json:"-" bson:"userId"`
+	BookId    primitive.ObjectID `json:"book_id" bson:"bookId"`
+	Text      string             `json:"text" bson:"text"`
+	EditedAt  time.Time          `json:"edited_at" bson:"editedAt"`
+	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
+}
+
+type NoteRevisionDiff struct {
+	From string `json:"from" example:"current"`
+	To   string `json:"to" example:"665f1f77bcf86cd799439011"`
+	Diff string `json:"diff"`
+}
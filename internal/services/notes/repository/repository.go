@@ -3,6 +3,7 @@ package repository
 import (
 	"context"
 	"errors"
+	"noto/internal/pkg/rank"
 	model "noto/internal/services/notes/model"
 	"noto/internal/utils"
 	"time"
@@ -14,27 +15,78 @@ import (
 )
 
 type NoteRepository interface {
-	GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNotesByCursor(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, cursor string, limit int) (*model.CursorNoteResponse, error)
 	CreateNote(note *model.NoteCreate) (*model.NoteCreate, error)
 	UpdateNote(note *model.NoteUpdate) (*model.NoteResponse, error)
 	DeleteNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) error
+	IterateNotes(userId primitive.ObjectID, bookId primitive.ObjectID, fn func(note *model.NoteResponse) error) error
+	GetNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) (*model.NoteResponse, error)
+	GetRevisions(userId primitive.ObjectID, noteId primitive.ObjectID) ([]model.NoteRevision, error)
+	GetRevision(userId primitive.ObjectID, noteId primitive.ObjectID, revisionId primitive.ObjectID) (*model.NoteRevision, error)
+	TrimRevisions(noteId primitive.ObjectID, keep int) error
+	MoveNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error)
+	CopyNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error)
+	BatchNotes(userId primitive.ObjectID, bookId primitive.ObjectID, items []model.NoteBatchItem) ([]model.NoteBatchResult, error)
+	SetPinned(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, pinned bool) (*model.NoteResponse, error)
+	ReorderNote(reorder *model.NoteReorder) (*model.NoteResponse, error)
+	EnsureIndexes() error
 }
 
 type NoteRepositoryImpl struct {
-	notes *mongo.Collection
+	db         *mongo.Database
+	notes      *mongo.Collection
+	revisions  *mongo.Collection
+	noteLabels *mongo.Collection
+}
+
+// displayOrder lists pinned notes first, then follows the manual order. Notes
+// that were never ranked sort before ranked ones, newest first.
+var displayOrder = bson.D{
+	{Key: "pinned", Value: -1},
+	{Key: "position", Value: 1},
+	{Key: "createdAt", Value: -1},
+	{Key: "_id", Value: -1},
 }
 
 func NewNoteRepository(db *mongo.Database) NoteRepository {
-	return &NoteRepositoryImpl{notes: db.Collection("notes")}
+	return &NoteRepositoryImpl{
+		db:         db,
+		notes:      db.Collection("notes"),
+		revisions:  db.Collection("note_revisions"),
+		noteLabels: db.Collection("note_labels"),
+	}
 }
 
-func (r *NoteRepositoryImpl) GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, page int, limit int) (*model.PaginatedNoteResponse, error) {
+func (r *NoteRepositoryImpl) EnsureIndexes() error {
+	_, err := r.revisions.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: bson.D{{Key: "noteId", Value: 1}, {Key: "createdAt", Value: -1}},
+	})
+	if err != nil {
+		return err
+	}
+
+	keys := bson.D{{Key: "userId", Value: 1}, {Key: "bookId", Value: 1}}
+	_, err = r.notes.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys: append(keys, displayOrder...),
+	})
+
+	return err
+}
+
+// GetNotes lists the notes of a book. The prefix of the query matches the
+// beginning of the note text, which is its title.
+func (r *NoteRepositoryImpl) GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, page int, limit int) (*model.PaginatedNoteResponse, error) {
+	if err := r.checkBook(context.Background(), userId, bookId); err != nil {
+		return nil, err
+	}
+
 	var notes []model.PaginatedNoteResponse
 
 	pipeline := mongo.Pipeline{
-		{{Key: "$match", Value: bson.M{"userId": userId, "bookId": bookId}}},
-		{{Key: "$sort", Value: bson.M{"createdAt": -1}}},
-		{{Key: "$facet", Value: utils.PaginationAggregate(page, limit)}},
+		{{Key: "$match", Value: notesMatch(userId, bookId, query)}},
+		{{Key: "$sort", Value: notesSort(query)}},
+		{{Key: "$facet", Value: utils.PaginationAggregateWith(page, limit, utils.NoteLabelStages())}},
 		{{Key: "$unwind", Value: "$metadata"}},
 	}
 
@@ -57,59 +109,1013 @@ func (r *NoteRepositoryImpl) GetNotes(userId primitive.ObjectID, bookId primitiv
 	return &notes[0], nil
 }
 
+// GetNotesByCursor is the keyset paginated variant of GetNotes.
+func (r *NoteRepositoryImpl) GetNotesByCursor(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, cursor string, limit int) (*model.CursorNoteResponse, error) {
+	if err := r.checkBook(context.Background(), userId, bookId); err != nil {
+		return nil, err
+	}
+
+	sort := notesSort(query)
+	keyset, err := utils.KeysetMatch(sort, cursor, utils.KeysetDocument(r.notes, bson.M{"userId": userId}))
+	if err != nil {
+		return nil, err
+	}
+
+	match := append(notesMatch(userId, bookId, query), bson.E{Key: "$and", Value: bson.A{keyset}})
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$sort", Value: sort}},
+		{{Key: "$limit", Value: limit + 1}},
+	}
+	pipeline = append(pipeline, utils.NoteLabelStages()...)
+
+	results, err := r.notes.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []bson.Raw
+	if err := results.All(context.Background(), &documents); err != nil {
+		return nil, err
+	}
+
+	page, next, err := utils.KeysetPage(sort, documents, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	notes := make([]model.NoteResponse, len(page))
+	for i, document := range page {
+		if err := bson.Unmarshal(document, &notes[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return &model.CursorNoteResponse{Data: notes, NextCursor: next}, nil
+}
+
+// liveBook reports whether the book belongs to the user and is not in the
+// trash. The notes of a trashed book are only reachable through the trash.
+func (r *NoteRepositoryImpl) liveBook(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID) (bool, error) {
+	count, err := r.db.Collection("books").CountDocuments(ctx, bson.M{"_id": bookId, "userId": userId, "deletedAt": nil})
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// checkBook is liveBook for paths addressing the book itself.
+func (r *NoteRepositoryImpl) checkBook(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	live, err := r.liveBook(ctx, userId, bookId)
+	if err != nil {
+		return err
+	}
+	if !live {
+		return errors.New("book not found")
+	}
+
+	return nil
+}
+
+// liveBookStages drops notes whose book is in the trash.
+func liveBookStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "books",
+			"localField":   "bookId",
+			"foreignField": "_id",
+			"as":           "book",
+		}}},
+		{{Key: "$match", Value: bson.M{"book": bson.M{"$elemMatch": bson.M{"deletedAt": nil}}}}},
+		{{Key: "$project", Value: bson.M{"book": 0}}},
+	}
+}
+
+// findNote loads a single note with its labels.
+func (r *NoteRepositoryImpl) findNote(ctx context.Context, filter bson.M) (*model.NoteResponse, error) {
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: filter}}}, utils.NoteLabelStages()...)
+	cursor, err := r.notes.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	var notes []model.NoteResponse
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+
+	if len(notes) == 0 {
+		return nil, mongo.ErrNoDocuments
+	}
+
+	return &notes[0], nil
+}
+
+func notesMatch(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery) bson.D {
+	match := bson.D{
+		{Key: "userId", Value: userId},
+		{Key: "bookId", Value: bookId},
+		{Key: "deletedAt", Value: nil},
+	}
+
+	return append(match, query.Match("text")...)
+}
+
+// notesSort keeps pinned notes on top of any requested sort.
+func notesSort(query *utils.ListQuery) bson.D {
+	if query.SortField == "" {
+		return displayOrder
+	}
+
+	return append(bson.D{{Key: "pinned", Value: -1}}, query.Sort(nil)...)
+}
+
+// CreateNote ranks the new note above every other note of the book.
 func (r *NoteRepositoryImpl) CreateNote(note *model.NoteCreate) (*model.NoteCreate, error) {
 	note.CreatedAt = time.Now()
 	note.UpdatedAt = time.Now()
+	note.Version = 1
+
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		positions, err := r.ranksAbove(ctx, note.UserId, note.BookId, 1)
+		if err != nil {
+			return err
+		}
+		note.Position = positions[0]
 
-	newNote, err := r.notes.InsertOne(context.Background(), note)
+		newNote, err := r.notes.InsertOne(ctx, note)
+		if err != nil {
+			return err
+		}
+
+		note.ID = newNote.InsertedID.(primitive.ObjectID)
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	note.ID = newNote.InsertedID.(primitive.ObjectID)
-
 	return note, nil
 }
 
+// UpdateNote replaces the text of a note and keeps the previous text as a
+// revision in the same transaction. When note.Version is set the update only
+// applies if the note is still at that version.
 func (r *NoteRepositoryImpl) UpdateNote(note *model.NoteUpdate) (*model.NoteResponse, error) {
-	filter := bson.M{"_id": note.ID, "userId": note.UserId, "bookId": note.BookId}
+	filter := bson.M{"_id": note.ID, "userId": note.UserId, "bookId": note.BookId, "deletedAt": nil}
 	update := bson.M{
 		"$set": bson.M{
 			"text":      note.Text,
 			"updatedAt": time.Now(),
 		},
+		"$inc": bson.M{"version": 1},
 	}
 
-	result := r.notes.FindOneAndUpdate(context.Background(), filter, update, options.FindOneAndUpdate().SetReturnDocument(options.After))
+	versioned := utils.MatchVersion(bson.M{"_id": note.ID, "userId": note.UserId, "bookId": note.BookId, "deletedAt": nil}, note.Version)
 
-	if result.Err() != nil {
-		if result.Err() == mongo.ErrNoDocuments {
-			return nil, errors.New("note not found")
+	var updatedNote *model.NoteResponse
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		live, err := r.liveBook(ctx, note.UserId, note.BookId)
+		if err != nil {
+			return err
 		}
-		return nil, result.Err()
-	}
+		if !live {
+			return errors.New("note not found")
+		}
+
+		result := r.notes.FindOneAndUpdate(ctx, versioned, update, options.FindOneAndUpdate().SetReturnDocument(options.Before))
 
-	var updatedNote model.NoteResponse
-	err := result.Decode(&updatedNote)
+		if result.Err() != nil {
+			if result.Err() != mongo.ErrNoDocuments {
+				return result.Err()
+			}
+			if note.Version != nil {
+				count, err := r.notes.CountDocuments(ctx, filter)
+				if err != nil {
+					return err
+				}
+				if count > 0 {
+					return errors.New("version conflict")
+				}
+			}
+			return errors.New("note not found")
+		}
+
+		var previousNote model.NoteResponse
+		if err := result.Decode(&previousNote); err != nil {
+			return err
+		}
+
+		if previousNote.Text != note.Text {
+			revision := &model.NoteRevision{
+				NoteId:    note.ID,
+				UserId:    note.UserId,
+				BookId:    previousNote.BookId,
+				Text:      previousNote.Text,
+				EditedAt:  previousNote.UpdatedAt,
+				CreatedAt: time.Now(),
+			}
+			if _, err := r.revisions.InsertOne(ctx, revision); err != nil {
+				return err
+			}
+		}
+
+		updatedNote, err = r.findNote(ctx, bson.M{"_id": note.ID})
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
-	return &updatedNote, nil
+	return updatedNote, nil
 }
 
+// DeleteNote moves the note to the trash.
 func (r *NoteRepositoryImpl) DeleteNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) error {
-	filter := bson.M{"_id": noteId, "userId": userId, "bookId": bookId}
+	filter := bson.M{"_id": noteId, "userId": userId, "bookId": bookId, "deletedAt": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"deletedAt": time.Now(),
+		},
+	}
+
+	deleted, err := r.notes.UpdateOne(context.Background(), filter, update)
+
+	if err != nil {
+		return err
+	}
+
+	if deleted.MatchedCount == 0 {
+		return errors.New("note not found")
+	}
+
+	return nil
+}
+
+// MoveNotes rewrites the book of the notes and ranks them above the notes of
+// the target book, keeping their order. Either every note is moved or, when
+// one of them is missing, none is.
+func (r *NoteRepositoryImpl) MoveNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	var moved []model.NoteResponse
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		if err := r.checkBooks(ctx, transfer); err != nil {
+			return err
+		}
+
+		filter := bson.M{"_id": bson.M{"$in": transfer.NoteIds}, "userId": transfer.UserId, "bookId": transfer.FromBookId, "deletedAt": nil}
+		ordered, err := r.existingIdsInOrder(ctx, filter)
+		if err != nil {
+			return err
+		}
+
+		if len(ordered) != len(transfer.NoteIds) {
+			return errors.New("note not found")
+		}
+
+		positions, err := r.ranksAbove(ctx, transfer.UserId, transfer.BookId, len(ordered))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		writes := make([]mongo.WriteModel, 0, len(ordered))
+		for i, noteId := range ordered {
+			writes = append(writes, mongo.NewUpdateOneModel().
+				SetFilter(bson.M{"_id": noteId}).
+				SetUpdate(bson.M{
+					"$set": bson.M{
+						"bookId":    transfer.BookId,
+						"position":  positions[i],
+						"updatedAt": now,
+					},
+					"$inc": bson.M{"version": 1},
+				}))
+		}
+
+		if _, err := r.notes.BulkWrite(ctx, writes); err != nil {
+			return err
+		}
+
+		revisions := bson.M{"noteId": bson.M{"$in": transfer.NoteIds}, "userId": transfer.UserId}
+		if _, err := r.revisions.UpdateMany(ctx, revisions, bson.M{"$set": bson.M{"bookId": transfer.BookId}}); err != nil {
+			return err
+		}
+
+		moved, err = r.findNotes(ctx, transfer.UserId, transfer.NoteIds)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return moved, nil
+}
+
+// CopyNotes inserts a copy of each note into the target book, ranked above its
+// notes in the order of the originals. The copies keep the labels but start
+// with a fresh version and without revision history.
+func (r *NoteRepositoryImpl) CopyNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	var copies []model.NoteResponse
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		if err := r.checkBooks(ctx, transfer); err != nil {
+			return err
+		}
+
+		filter := bson.M{"_id": bson.M{"$in": transfer.NoteIds}, "userId": transfer.UserId, "bookId": transfer.FromBookId, "deletedAt": nil}
+		var notes []model.NoteResponse
+		cursor, err := r.notes.Find(ctx, filter, options.Find().SetSort(displayOrder))
+		if err != nil {
+			return err
+		}
+		if err := cursor.All(ctx, &notes); err != nil {
+			return err
+		}
+
+		if len(notes) != len(transfer.NoteIds) {
+			return errors.New("note not found")
+		}
+
+		positions, err := r.ranksAbove(ctx, transfer.UserId, transfer.BookId, len(notes))
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		documents := make([]interface{}, 0, len(notes))
+		copyIds := make(map[primitive.ObjectID]primitive.ObjectID, len(notes))
+		for i, note := range notes {
+			noteId, err := primitive.ObjectIDFromHex(note.ID)
+			if err != nil {
+				return err
+			}
+
+			copyIds[noteId] = primitive.NewObjectID()
+			documents = append(documents, &model.NoteCreate{
+				ID:        copyIds[noteId],
+				UserId:    transfer.UserId,
+				BookId:    transfer.BookId,
+				Text:      note.Text,
+				Pinned:    note.Pinned,
+				Color:     note.Color,
+				Position:  positions[i],
+				CreatedAt: now,
+				UpdatedAt: now,
+				Version:   1,
+			})
+		}
+
+		inserted, err := r.notes.InsertMany(ctx, documents)
+		if err != nil {
+			return err
+		}
+
+		if err := r.copyLabels(ctx, transfer.UserId, copyIds, now); err != nil {
+			return err
+		}
+
+		ids := make([]primitive.ObjectID, 0, len(inserted.InsertedIDs))
+		for _, id := range inserted.InsertedIDs {
+			ids = append(ids, id.(primitive.ObjectID))
+		}
+
+		copies, err = r.findNotes(ctx, transfer.UserId, ids)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return copies, nil
+}
+
+// copyLabels gives every copy the labels of its original note.
+func (r *NoteRepositoryImpl) copyLabels(ctx context.Context, userId primitive.ObjectID, copyIds map[primitive.ObjectID]primitive.ObjectID, now time.Time) error {
+	noteIds := make([]primitive.ObjectID, 0, len(copyIds))
+	for noteId := range copyIds {
+		noteIds = append(noteIds, noteId)
+	}
+
+	cursor, err := r.noteLabels.Find(ctx, bson.M{"noteId": bson.M{"$in": noteIds}, "userId": userId})
+	if err != nil {
+		return err
+	}
+
+	var links []struct {
+		NoteId  primitive.ObjectID `bson:"noteId"`
+		LabelId primitive.ObjectID `bson:"labelId"`
+	}
+	if err := cursor.All(ctx, &links); err != nil {
+		return err
+	}
+
+	if len(links) == 0 {
+		return nil
+	}
+
+	documents := make([]interface{}, 0, len(links))
+	for _, link := range links {
+		documents = append(documents, bson.M{
+			"noteId":    copyIds[link.NoteId],
+			"labelId":   link.LabelId,
+			"userId":    userId,
+			"createdAt": now,
+		})
+	}
+
+	_, err = r.noteLabels.InsertMany(ctx, documents)
+	return err
+}
+
+// BatchNotes runs every item whose note, target book and label belong to the
+// user as one ordered BulkWrite per collection inside a transaction. Items
+// that fail those checks are reported and skipped; a write error aborts all.
+// Items are checked in order, so a note deleted by an earlier item is not
+// found by the later ones.
+func (r *NoteRepositoryImpl) BatchNotes(userId primitive.ObjectID, bookId primitive.ObjectID, items []model.NoteBatchItem) ([]model.NoteBatchResult, error) {
+	var results []model.NoteBatchResult
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		results = make([]model.NoteBatchResult, len(items))
+
+		if err := r.checkBook(ctx, userId, bookId); err != nil {
+			return err
+		}
+
+		noteIds := []primitive.ObjectID{}
+		bookIds := []primitive.ObjectID{}
+		labelIds := []primitive.ObjectID{}
+		for _, item := range items {
+			noteIds = append(noteIds, item.NoteId)
+			switch item.Op {
+			case model.BatchMove:
+				bookIds = append(bookIds, item.BookId)
+			case model.BatchLabel, model.BatchUnlabel:
+				labelIds = append(labelIds, item.LabelId)
+			}
+		}
+
+		notes, err := r.existingIds(ctx, r.notes, bson.M{"_id": bson.M{"$in": noteIds}, "userId": userId, "bookId": bookId, "deletedAt": nil})
+		if err != nil {
+			return err
+		}
+		books, err := r.existingIds(ctx, r.db.Collection("books"), bson.M{"_id": bson.M{"$in": bookIds}, "userId": userId, "deletedAt": nil})
+		if err != nil {
+			return err
+		}
+		labels, err := r.existingIds(ctx, r.db.Collection("labels"), bson.M{"_id": bson.M{"$in": labelIds}, "userId": userId})
+		if err != nil {
+			return err
+		}
+
+		now := time.Now()
+		var noteWrites, labelWrites []mongo.WriteModel
+		moved := map[primitive.ObjectID]primitive.ObjectID{}
+		movedOrder := []primitive.ObjectID{}
+		for i, item := range items {
+			results[i] = model.NoteBatchResult{Op: item.Op, NoteId: item.NoteId.Hex()}
+
+			switch {
+			case !notes[item.NoteId]:
+				results[i].Error = "note not found"
+				continue
+			case item.Op == model.BatchMove && !books[item.BookId]:
+				results[i].Error = "target book not found"
+				continue
+			case (item.Op == model.BatchLabel || item.Op == model.BatchUnlabel) && !labels[item.LabelId]:
+				results[i].Error = "label not found"
+				continue
+			}
+
+			filter := bson.M{"_id": item.NoteId, "userId": userId}
+			switch item.Op {
+			case model.BatchDelete:
+				delete(notes, item.NoteId)
+				noteWrites = append(noteWrites, mongo.NewUpdateOneModel().SetFilter(filter).
+					SetUpdate(bson.M{"$set": bson.M{"deletedAt": now}, "$inc": bson.M{"version": 1}}))
+			case model.BatchMove:
+				if _, ok := moved[item.NoteId]; !ok {
+					movedOrder = append(movedOrder, item.NoteId)
+				}
+				moved[item.NoteId] = item.BookId
+				noteWrites = append(noteWrites, mongo.NewUpdateOneModel().SetFilter(filter).
+					SetUpdate(bson.M{"$set": bson.M{"bookId": item.BookId, "updatedAt": now}, "$inc": bson.M{"version": 1}}))
+			case model.BatchPin:
+				noteWrites = append(noteWrites, mongo.NewUpdateOneModel().SetFilter(filter).
+					SetUpdate(bson.M{"$set": bson.M{"pinned": item.Pinned, "updatedAt": now}, "$inc": bson.M{"version": 1}}))
+			case model.BatchColor:
+				update := bson.M{"$set": bson.M{"color": item.Color, "updatedAt": now}, "$inc": bson.M{"version": 1}}
+				if item.Color == "" {
+					update = bson.M{"$set": bson.M{"updatedAt": now}, "$unset": bson.M{"color": ""}, "$inc": bson.M{"version": 1}}
+				}
+				noteWrites = append(noteWrites, mongo.NewUpdateOneModel().SetFilter(filter).SetUpdate(update))
+			case model.BatchLabel:
+				link := bson.M{"noteId": item.NoteId, "labelId": item.LabelId, "userId": userId}
+				labelWrites = append(labelWrites, mongo.NewUpdateOneModel().SetFilter(link).
+					SetUpdate(bson.M{"$setOnInsert": bson.M{"createdAt": now}}).SetUpsert(true))
+			case model.BatchUnlabel:
+				link := bson.M{"noteId": item.NoteId, "labelId": item.LabelId, "userId": userId}
+				labelWrites = append(labelWrites, mongo.NewDeleteOneModel().SetFilter(link))
+			}
+
+			results[i].Success = true
+		}
+
+		// Moved notes are ranked above the notes of the book of their last
+		// move, in the order they were first moved.
+		movedTo := map[primitive.ObjectID][]primitive.ObjectID{}
+		targets := []primitive.ObjectID{}
+		for _, noteId := range movedOrder {
+			target := moved[noteId]
+			if _, ok := movedTo[target]; !ok {
+				targets = append(targets, target)
+			}
+			movedTo[target] = append(movedTo[target], noteId)
+		}
+
+		for _, target := range targets {
+			positions, err := r.ranksAbove(ctx, userId, target, len(movedTo[target]))
+			if err != nil {
+				return err
+			}
+
+			for i, noteId := range movedTo[target] {
+				noteWrites = append(noteWrites, mongo.NewUpdateOneModel().
+					SetFilter(bson.M{"_id": noteId, "userId": userId}).
+					SetUpdate(bson.M{"$set": bson.M{"position": positions[i]}}))
+			}
+		}
+
+		if len(noteWrites) > 0 {
+			if _, err := r.notes.BulkWrite(ctx, noteWrites, options.BulkWrite().SetOrdered(true)); err != nil {
+				return err
+			}
+		}
+
+		if len(labelWrites) > 0 {
+			if _, err := r.noteLabels.BulkWrite(ctx, labelWrites, options.BulkWrite().SetOrdered(true)); err != nil {
+				return err
+			}
+		}
+
+		// Revisions follow their note into the book of its last move.
+		var revisionWrites []mongo.WriteModel
+		for _, target := range targets {
+			ids := movedTo[target]
+			revisionWrites = append(revisionWrites, mongo.NewUpdateManyModel().
+				SetFilter(bson.M{"noteId": bson.M{"$in": ids}}).
+				SetUpdate(bson.M{"$set": bson.M{"bookId": target}}))
+		}
+
+		if len(revisionWrites) > 0 {
+			if _, err := r.revisions.BulkWrite(ctx, revisionWrites); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
 
-	deleted, err := r.notes.DeleteOne(context.Background(), filter)
+	return results, nil
+}
+
+// existingIds returns the ids of the documents matching filter.
+func (r *NoteRepositoryImpl) existingIds(ctx context.Context, collection *mongo.Collection, filter bson.M) (map[primitive.ObjectID]bool, error) {
+	cursor, err := collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	ids := make(map[primitive.ObjectID]bool, len(documents))
+	for _, document := range documents {
+		ids[document.ID] = true
+	}
+
+	return ids, nil
+}
+
+func (r *NoteRepositoryImpl) SetPinned(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, pinned bool) (*model.NoteResponse, error) {
+	live, err := r.liveBook(context.Background(), userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if !live {
+		return nil, errors.New("note not found")
+	}
+
+	filter := bson.M{"_id": noteId, "userId": userId, "bookId": bookId, "deletedAt": nil}
+	update := bson.M{
+		"$set": bson.M{
+			"pinned":    pinned,
+			"updatedAt": time.Now(),
+		},
+		"$inc": bson.M{"version": 1},
+	}
+
+	updated, err := r.notes.UpdateOne(context.Background(), filter, update)
+	if err != nil {
+		return nil, err
+	}
+
+	if updated.MatchedCount == 0 {
+		return nil, errors.New("note not found")
+	}
+
+	return r.findNote(context.Background(), bson.M{"_id": noteId})
+}
+
+// ReorderNote gives the note a rank between its new neighbours. Books with
+// notes from before manual ordering are ranked in their current order first.
+func (r *NoteRepositoryImpl) ReorderNote(reorder *model.NoteReorder) (*model.NoteResponse, error) {
+	var note *model.NoteResponse
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		if err := r.lockBook(ctx, reorder.UserId, reorder.BookId); err != nil {
+			return err
+		}
+
+		if err := r.rankUnranked(ctx, reorder.UserId, reorder.BookId); err != nil {
+			return err
+		}
+
+		after, err := r.neighbourPosition(ctx, reorder, reorder.AfterId)
+		if err != nil {
+			return err
+		}
+
+		before, err := r.neighbourPosition(ctx, reorder, reorder.BeforeId)
+		if err != nil {
+			return err
+		}
+
+		if reorder.AfterId == nil {
+			if after, err = r.adjacentPosition(ctx, reorder, before, "$lt"); err != nil {
+				return err
+			}
+		}
+
+		if reorder.BeforeId == nil {
+			if before, err = r.adjacentPosition(ctx, reorder, after, "$gt"); err != nil {
+				return err
+			}
+		}
+
+		position, err := rank.Between(after, before)
+		if err != nil {
+			return errors.New("after_id must come before before_id")
+		}
+
+		filter := bson.M{"_id": reorder.ID, "userId": reorder.UserId, "bookId": reorder.BookId, "deletedAt": nil}
+		update := bson.M{
+			"$set": bson.M{
+				"position":  position,
+				"updatedAt": time.Now(),
+			},
+			"$inc": bson.M{"version": 1},
+		}
+
+		updated, err := r.notes.UpdateOne(ctx, filter, update)
+		if err != nil {
+			return err
+		}
 
+		if updated.MatchedCount == 0 {
+			return errors.New("note not found")
+		}
+
+		note, err = r.findNote(ctx, bson.M{"_id": reorder.ID})
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return note, nil
+}
+
+// neighbourPosition returns the rank of a neighbouring note, or "" for the
+// start or end of the list when there is no neighbour.
+func (r *NoteRepositoryImpl) neighbourPosition(ctx context.Context, reorder *model.NoteReorder, noteId *primitive.ObjectID) (string, error) {
+	if noteId == nil {
+		return "", nil
+	}
+
+	var neighbour model.NoteResponse
+	err := r.notes.FindOne(ctx, bson.M{"_id": noteId, "userId": reorder.UserId, "bookId": reorder.BookId, "deletedAt": nil}).Decode(&neighbour)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return "", errors.New("note not found")
+		}
+		return "", err
+	}
+
+	return neighbour.Position, nil
+}
+
+// adjacentPosition returns the closest rank below ("$lt") or above ("$gt")
+// position among the other notes of the book, or "" at either end.
+func (r *NoteRepositoryImpl) adjacentPosition(ctx context.Context, reorder *model.NoteReorder, position string, direction string) (string, error) {
+	filter := bson.M{
+		"userId":    reorder.UserId,
+		"bookId":    reorder.BookId,
+		"deletedAt": nil,
+		"_id":       bson.M{"$ne": reorder.ID},
+		"position":  bson.M{"$gt": ""},
+	}
+
+	sort := 1
+	if direction == "$lt" {
+		sort = -1
+		filter["position"] = bson.M{"$gt": "", "$lt": position}
+	} else if position != "" {
+		filter["position"] = bson.M{"$gt": position}
+	}
+
+	var adjacent model.NoteResponse
+	err := r.notes.FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"position": sort})).Decode(&adjacent)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+
+	return adjacent.Position, err
+}
+
+// ranksAbove returns n ascending ranks that sort above every ranked note of
+// the book. It writes to the book first, so concurrent transactions ranking
+// notes in the same book conflict and are retried instead of handing out the
+// same ranks.
+func (r *NoteRepositoryImpl) ranksAbove(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID, n int) ([]string, error) {
+	if err := r.lockBook(ctx, userId, bookId); err != nil {
+		return nil, err
+	}
+
+	first, err := r.firstPosition(ctx, userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+
+	return rank.SpreadBetween("", first, n)
+}
+
+// lockBook writes to the book so concurrent transactions ranking its notes
+// conflict and retry instead of picking the same rank. It also confirms the
+// book belongs to the user and is not in the trash.
+func (r *NoteRepositoryImpl) lockBook(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	filter := bson.M{"_id": bookId, "userId": userId, "deletedAt": nil}
+	updated, err := r.db.Collection("books").UpdateOne(ctx, filter, bson.M{"$inc": bson.M{"rankVersion": 1}})
 	if err != nil {
 		return err
 	}
 
-	if deleted.DeletedCount == 0 {
-		return errors.New("note not found or not deleted")
+	if updated.MatchedCount == 0 {
+		return errors.New("book not found")
 	}
 
 	return nil
 }
+
+// firstPosition returns the lowest rank in the book, or "" when no note is
+// ranked yet.
+func (r *NoteRepositoryImpl) firstPosition(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID) (string, error) {
+	filter := bson.M{"userId": userId, "bookId": bookId, "deletedAt": nil, "position": bson.M{"$gt": ""}}
+
+	var first model.NoteResponse
+	err := r.notes.FindOne(ctx, filter, options.FindOne().SetSort(bson.M{"position": 1})).Decode(&first)
+	if err == mongo.ErrNoDocuments {
+		return "", nil
+	}
+
+	return first.Position, err
+}
+
+// rankUnranked spreads fresh ranks over the whole book, keeping the current
+// display order, when any note of it has no rank yet.
+func (r *NoteRepositoryImpl) rankUnranked(ctx context.Context, userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	filter := bson.M{"userId": userId, "bookId": bookId, "deletedAt": nil}
+
+	unranked, err := r.notes.CountDocuments(ctx, bson.M{"userId": userId, "bookId": bookId, "deletedAt": nil, "position": nil})
+	if err != nil || unranked == 0 {
+		return err
+	}
+
+	ids, err := r.existingIdsInOrder(ctx, filter)
+	if err != nil {
+		return err
+	}
+
+	writes := make([]mongo.WriteModel, 0, len(ids))
+	for i, position := range rank.Spread(len(ids)) {
+		writes = append(writes, mongo.NewUpdateOneModel().
+			SetFilter(bson.M{"_id": ids[i]}).
+			SetUpdate(bson.M{"$set": bson.M{"position": position}}))
+	}
+
+	_, err = r.notes.BulkWrite(ctx, writes)
+	return err
+}
+
+func (r *NoteRepositoryImpl) existingIdsInOrder(ctx context.Context, filter bson.M) ([]primitive.ObjectID, error) {
+	opts := options.Find().SetSort(displayOrder).SetProjection(bson.M{"_id": 1})
+	cursor, err := r.notes.Find(ctx, filter, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document.ID)
+	}
+
+	return ids, nil
+}
+
+// checkBooks makes sure the caller owns both the source and the target book.
+func (r *NoteRepositoryImpl) checkBooks(ctx context.Context, transfer *model.NoteTransfer) error {
+	books := r.db.Collection("books")
+
+	for _, book := range []struct {
+		id      primitive.ObjectID
+		message string
+	}{
+		{transfer.FromBookId, "book not found"},
+		{transfer.BookId, "target book not found"},
+	} {
+		count, err := books.CountDocuments(ctx, bson.M{"_id": book.id, "userId": transfer.UserId, "deletedAt": nil})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New(book.message)
+		}
+	}
+
+	return nil
+}
+
+// findNotes loads notes in the order of noteIds.
+func (r *NoteRepositoryImpl) findNotes(ctx context.Context, userId primitive.ObjectID, noteIds []primitive.ObjectID) ([]model.NoteResponse, error) {
+	var notes []model.NoteResponse
+	pipeline := append(mongo.Pipeline{{{Key: "$match", Value: bson.M{"_id": bson.M{"$in": noteIds}, "userId": userId}}}}, utils.NoteLabelStages()...)
+	cursor, err := r.notes.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, err
+	}
+	if err := cursor.All(ctx, &notes); err != nil {
+		return nil, err
+	}
+
+	byId := make(map[string]model.NoteResponse, len(notes))
+	for _, note := range notes {
+		byId[note.ID] = note
+	}
+
+	ordered := make([]model.NoteResponse, 0, len(noteIds))
+	for _, noteId := range noteIds {
+		if note, ok := byId[noteId.Hex()]; ok {
+			ordered = append(ordered, note)
+		}
+	}
+
+	return ordered, nil
+}
+
+// IterateNotes calls fn for every note of a book, oldest first, without loading
+// them all into memory. A nil bookId iterates over the notes of every book
+// that is not in the trash. Notes in the trash are skipped.
+func (r *NoteRepositoryImpl) IterateNotes(userId primitive.ObjectID, bookId primitive.ObjectID, fn func(note *model.NoteResponse) error) error {
+	filter := bson.M{"userId": userId, "deletedAt": nil}
+	if !bookId.IsZero() {
+		filter["bookId"] = bookId
+	}
+
+	pipeline := mongo.Pipeline{{{Key: "$match", Value: filter}}}
+	if bookId.IsZero() {
+		pipeline = append(pipeline, liveBookStages()...)
+	}
+	pipeline = append(pipeline, bson.D{{Key: "$sort", Value: bson.D{{Key: "createdAt", Value: 1}}}})
+	pipeline = append(pipeline, utils.NoteLabelStages()...)
+	cursor, err := r.notes.Aggregate(context.Background(), pipeline, options.Aggregate().SetAllowDiskUse(true))
+	if err != nil {
+		return err
+	}
+	defer cursor.Close(context.Background())
+
+	for cursor.Next(context.Background()) {
+		var note model.NoteResponse
+		if err := cursor.Decode(&note); err != nil {
+			return err
+		}
+
+		if err := fn(&note); err != nil {
+			return err
+		}
+	}
+
+	return cursor.Err()
+}
+
+// GetNote returns a note of the book, unless the note or its book is in the
+// trash.
+func (r *NoteRepositoryImpl) GetNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) (*model.NoteResponse, error) {
+	live, err := r.liveBook(context.Background(), userId, bookId)
+	if err != nil {
+		return nil, err
+	}
+	if !live {
+		return nil, errors.New("note not found")
+	}
+
+	filter := bson.M{"_id": noteId, "userId": userId, "bookId": bookId, "deletedAt": nil}
+
+	note, err := r.findNote(context.Background(), filter)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("note not found")
+		}
+		return nil, err
+	}
+
+	return note, nil
+}
+
+func (r *NoteRepositoryImpl) GetRevisions(userId primitive.ObjectID, noteId primitive.ObjectID) ([]model.NoteRevision, error) {
+	var revisions []model.NoteRevision
+
+	opts := options.Find().SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}})
+	cursor, err := r.revisions.Find(context.Background(), bson.M{"noteId": noteId, "userId": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &revisions); err != nil {
+		return nil, err
+	}
+
+	if len(revisions) == 0 {
+		return []model.NoteRevision{}, nil
+	}
+
+	return revisions, nil
+}
+
+func (r *NoteRepositoryImpl) GetRevision(userId primitive.ObjectID, noteId primitive.ObjectID, revisionId primitive.ObjectID) (*model.NoteRevision, error) {
+	filter := bson.M{"_id": revisionId, "noteId": noteId, "userId": userId}
+
+	var revision model.NoteRevision
+	err := r.revisions.FindOne(context.Background(), filter).Decode(&revision)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("revision not found")
+		}
+		return nil, err
+	}
+
+	return &revision, nil
+}
+
+// TrimRevisions deletes all but the newest keep revisions of a note.
+func (r *NoteRepositoryImpl) TrimRevisions(noteId primitive.ObjectID, keep int) error {
+	opts := options.Find().
+		SetSort(bson.D{{Key: "createdAt", Value: -1}, {Key: "_id", Value: -1}}).
+		SetSkip(int64(keep)).
+		SetProjection(bson.M{"_id": 1})
+
+	cursor, err := r.revisions.Find(context.Background(), bson.M{"noteId": noteId}, opts)
+	if err != nil {
+		return err
+	}
+
+	var expired []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(context.Background(), &expired); err != nil {
+		return err
+	}
+
+	if len(expired) == 0 {
+		return nil
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(expired))
+	for _, revision := range expired {
+		ids = append(ids, revision.ID)
+	}
+
+	_, err = r.revisions.DeleteMany(context.Background(), bson.M{"_id": bson.M{"$in": ids}})
+	return err
+}
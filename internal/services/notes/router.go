@@ -1,21 +1,42 @@
 package notes_router
 
 import (
+	"log"
 	"noto/internal/config"
+	"noto/internal/middleware"
 	"noto/internal/services/notes/handler"
 	"noto/internal/services/notes/repository"
 	"noto/internal/services/notes/service"
+	"noto/internal/utils"
 
 	"github.com/gofiber/fiber/v2"
 )
 
 func NotesRouter(router fiber.Router) {
 	var repo = repository.NewNoteRepository(config.DB)
-	var serv = service.NewNoteService(repo)
+	var serv = service.NewNoteService(repo, config.NoteRevisionLimit)
 	var hand = handler.NewNoteHandler(serv)
 
-	router.Get("/books/:bookId/notes", hand.GetNotes)
-	router.Post("/books/:bookId/notes", hand.CreateNote)
-	router.Patch("/books/:bookId/notes/:noteId", hand.UpdateNote)
-	router.Delete("/books/:bookId/notes/:noteId", hand.DeleteNote)
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create note indexes:", err)
+	}
+
+	var read = middleware.RequireScope(utils.ScopeNotesRead)
+	var write = middleware.RequireScope(utils.ScopeNotesWrite)
+
+	router.Get("/books/:bookId/notes", read, hand.GetNotes)
+	router.Post("/books/:bookId/notes", write, hand.CreateNote)
+	router.Post("/books/:bookId/notes/move", write, hand.MoveNotes)
+	router.Post("/books/:bookId/notes/copy", write, hand.CopyNotes)
+	router.Post("/books/:bookId/notes/batch", write, hand.BatchNotes)
+	router.Get("/books/:bookId/notes/:noteId", read, hand.GetNote)
+	router.Patch("/books/:bookId/notes/:noteId", write, hand.UpdateNote)
+	router.Delete("/books/:bookId/notes/:noteId", write, hand.DeleteNote)
+	router.Patch("/books/:bookId/notes/:noteId/pin", write, hand.PinNote)
+	router.Post("/books/:bookId/notes/:noteId/reorder", write, hand.ReorderNote)
+	router.Post("/books/:bookId/notes/:noteId/move", write, hand.MoveNote)
+	router.Post("/books/:bookId/notes/:noteId/copy", write, hand.CopyNote)
+	router.Get("/books/:bookId/notes/:noteId/revisions", read, hand.GetRevisions)
+	router.Get("/books/:bookId/notes/:noteId/revisions/diff", read, hand.DiffRevisions)
+	router.Post("/books/:bookId/notes/:noteId/revisions/:revisionId/restore", write, hand.RestoreRevision)
 }
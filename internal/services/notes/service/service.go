@@ -1,39 +1,324 @@
 package service
 
 import (
+	"errors"
+	"fmt"
+	"noto/internal/pkg/diff"
 	model "noto/internal/services/notes/model"
 	"noto/internal/services/notes/repository"
+	"noto/internal/utils"
+	"strconv"
 
 	"go.mongodb.org/mongo-driver/bson/primitive"
 )
 
+// CurrentRevision refers to the current text of a note when diffing revisions.
+const CurrentRevision = "current"
+
+const diffContext = 3
+
+// MaxBulkNotes caps how many notes a single bulk request may touch.
+const MaxBulkNotes = 500
+
 type NoteService interface {
-	GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, page int, limit int) (*model.PaginatedNoteResponse, error)
+	GetNotesByCursor(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, cursor string, limit int) (*model.CursorNoteResponse, error)
+	GetNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) (*model.NoteResponse, error)
 	CreateNote(note *model.NoteCreate) (*model.NoteCreate, error)
 	UpdateNote(note *model.NoteUpdate) (*model.NoteResponse, error)
 	DeleteNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) error
+	GetRevisions(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) ([]model.NoteRevision, error)
+	DiffRevisions(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, from string, to string) (*model.NoteRevisionDiff, error)
+	RestoreRevision(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, revisionId primitive.ObjectID) (*model.NoteResponse, error)
+	MoveNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error)
+	CopyNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error)
+	BatchNotes(userId primitive.ObjectID, bookId primitive.ObjectID, batch *model.NoteBatch) (*model.NoteBatchResponse, error)
+	SetPinned(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, pinned bool) (*model.NoteResponse, error)
+	ReorderNote(reorder *model.NoteReorder) (*model.NoteResponse, error)
 }
 
 type NoteServiceImpl struct {
-	noteRepo repository.NoteRepository
+	noteRepo      repository.NoteRepository
+	revisionLimit int
+}
+
+func NewNoteService(noteRepo repository.NoteRepository, revisionLimit int) NoteService {
+	return &NoteServiceImpl{noteRepo: noteRepo, revisionLimit: revisionLimit}
+}
+
+func (r *NoteServiceImpl) GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, page int, limit int) (*model.PaginatedNoteResponse, error) {
+	return r.noteRepo.GetNotes(userId, bookId, query, page, limit)
 }
 
-func NewNoteService(noteRepo repository.NoteRepository) NoteService {
-	return &NoteServiceImpl{noteRepo: noteRepo}
+func (r *NoteServiceImpl) GetNotesByCursor(userId primitive.ObjectID, bookId primitive.ObjectID, query *utils.ListQuery, cursor string, limit int) (*model.CursorNoteResponse, error) {
+	return r.noteRepo.GetNotesByCursor(userId, bookId, query, cursor, limit)
 }
 
-func (r *NoteServiceImpl) GetNotes(userId primitive.ObjectID, bookId primitive.ObjectID, page int, limit int) (*model.PaginatedNoteResponse, error) {
-	return r.noteRepo.GetNotes(userId, bookId, page, limit)
+func (r *NoteServiceImpl) GetNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) (*model.NoteResponse, error) {
+	return r.noteRepo.GetNote(userId, bookId, noteId)
 }
 
 func (r *NoteServiceImpl) CreateNote(note *model.NoteCreate) (*model.NoteCreate, error) {
+	if note.Color != "" && !utils.IsHexColor(note.Color) {
+		return nil, errors.New("color must look like #rrggbb")
+	}
+
 	return r.noteRepo.CreateNote(note)
 }
 
 func (r *NoteServiceImpl) UpdateNote(note *model.NoteUpdate) (*model.NoteResponse, error) {
-	return r.noteRepo.UpdateNote(note)
+	updatedNote, err := r.noteRepo.UpdateNote(note)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.revisionLimit > 0 {
+		if err := r.noteRepo.TrimRevisions(note.ID, r.revisionLimit); err != nil {
+			return nil, err
+		}
+	}
+
+	return updatedNote, nil
 }
 
 func (r *NoteServiceImpl) DeleteNote(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) error {
 	return r.noteRepo.DeleteNote(userId, bookId, noteId)
 }
+
+func (r *NoteServiceImpl) GetRevisions(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID) ([]model.NoteRevision, error) {
+	if _, err := r.noteRepo.GetNote(userId, bookId, noteId); err != nil {
+		return nil, err
+	}
+
+	return r.noteRepo.GetRevisions(userId, noteId)
+}
+
+func (r *NoteServiceImpl) DiffRevisions(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, from string, to string) (*model.NoteRevisionDiff, error) {
+	if from == "" {
+		return nil, errors.New("from is required")
+	}
+
+	if to == "" {
+		to = CurrentRevision
+	}
+
+	note, err := r.noteRepo.GetNote(userId, bookId, noteId)
+	if err != nil {
+		return nil, err
+	}
+
+	fromText, err := r.revisionText(userId, noteId, note, from)
+	if err != nil {
+		return nil, err
+	}
+
+	toText, err := r.revisionText(userId, noteId, note, to)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.NoteRevisionDiff{
+		From: from,
+		To:   to,
+		Diff: diff.Unified(from, to, fromText, toText, diffContext),
+	}, nil
+}
+
+func (r *NoteServiceImpl) revisionText(userId primitive.ObjectID, noteId primitive.ObjectID, note *model.NoteResponse, revision string) (string, error) {
+	if revision == CurrentRevision {
+		return note.Text, nil
+	}
+
+	revisionId, err := primitive.ObjectIDFromHex(revision)
+	if err != nil {
+		return "", errors.New("invalid revision id")
+	}
+
+	found, err := r.noteRepo.GetRevision(userId, noteId, revisionId)
+	if err != nil {
+		return "", err
+	}
+
+	return found.Text, nil
+}
+
+// RestoreRevision makes an old revision the current text. The text it
+// replaces is kept as a new revision, so a restore can itself be undone.
+func (r *NoteServiceImpl) RestoreRevision(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, revisionId primitive.ObjectID) (*model.NoteResponse, error) {
+	if _, err := r.noteRepo.GetNote(userId, bookId, noteId); err != nil {
+		return nil, err
+	}
+
+	revision, err := r.noteRepo.GetRevision(userId, noteId, revisionId)
+	if err != nil {
+		return nil, err
+	}
+
+	return r.UpdateNote(&model.NoteUpdate{
+		ID:     noteId,
+		UserId: userId,
+		BookId: bookId,
+		Text:   revision.Text,
+	})
+}
+
+func (r *NoteServiceImpl) MoveNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	if err := validateTransfer(transfer); err != nil {
+		return nil, err
+	}
+
+	if transfer.FromBookId == transfer.BookId {
+		return nil, errors.New("notes are already in this book")
+	}
+
+	return r.noteRepo.MoveNotes(transfer)
+}
+
+func (r *NoteServiceImpl) CopyNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	if err := validateTransfer(transfer); err != nil {
+		return nil, err
+	}
+
+	return r.noteRepo.CopyNotes(transfer)
+}
+
+// validateTransfer checks the note ids and drops duplicates so each note is
+// moved or copied once.
+func validateTransfer(transfer *model.NoteTransfer) error {
+	if transfer.BookId.IsZero() {
+		return errors.New("book_id is required")
+	}
+
+	if len(transfer.NoteIds) == 0 {
+		return errors.New("note_ids is required")
+	}
+
+	seen := make(map[primitive.ObjectID]bool, len(transfer.NoteIds))
+	noteIds := make([]primitive.ObjectID, 0, len(transfer.NoteIds))
+	for _, noteId := range transfer.NoteIds {
+		if !seen[noteId] {
+			seen[noteId] = true
+			noteIds = append(noteIds, noteId)
+		}
+	}
+
+	if len(noteIds) > MaxBulkNotes {
+		return fmt.Errorf("at most %d notes can be changed at once", MaxBulkNotes)
+	}
+
+	transfer.NoteIds = noteIds
+	return nil
+}
+
+// BatchNotes expands the operations into one item per note. Malformed note ids
+// are reported in the results, malformed operations reject the whole batch.
+func (r *NoteServiceImpl) BatchNotes(userId primitive.ObjectID, bookId primitive.ObjectID, batch *model.NoteBatch) (*model.NoteBatchResponse, error) {
+	if len(batch.Operations) == 0 {
+		return nil, errors.New("operations is required")
+	}
+
+	var results []model.NoteBatchResult
+	var items []model.NoteBatchItem
+	var positions []int
+	for index, operation := range batch.Operations {
+		template, err := batchItem(operation)
+		if err != nil {
+			return nil, fmt.Errorf("invalid operation %d: %s", index+1, err.Error())
+		}
+
+		for _, id := range operation.NoteIds {
+			result := model.NoteBatchResult{Op: operation.Op, NoteId: id}
+
+			noteId, err := primitive.ObjectIDFromHex(id)
+			if err != nil {
+				result.Error = "invalid id format"
+			} else {
+				item := template
+				item.NoteId = noteId
+				items = append(items, item)
+				positions = append(positions, len(results))
+			}
+
+			results = append(results, result)
+		}
+	}
+
+	if len(results) > MaxBulkNotes {
+		return nil, fmt.Errorf("at most %d notes can be changed at once", MaxBulkNotes)
+	}
+
+	if len(items) > 0 {
+		executed, err := r.noteRepo.BatchNotes(userId, bookId, items)
+		if err != nil {
+			return nil, err
+		}
+
+		for i, result := range executed {
+			results[positions[i]] = result
+		}
+	}
+
+	return &model.NoteBatchResponse{Results: results}, nil
+}
+
+// batchItem validates the parameters of an operation and returns the item
+// every one of its notes is executed with.
+func batchItem(operation model.NoteBatchOperation) (model.NoteBatchItem, error) {
+	item := model.NoteBatchItem{Op: operation.Op}
+
+	if len(operation.NoteIds) == 0 {
+		return item, errors.New("note_ids is required")
+	}
+
+	switch operation.Op {
+	case model.BatchDelete:
+	case model.BatchMove:
+		bookId, err := primitive.ObjectIDFromHex(operation.BookId)
+		if err != nil {
+			return item, errors.New("book_id is required")
+		}
+		item.BookId = bookId
+	case model.BatchPin:
+		item.Pinned = operation.Pinned == nil || *operation.Pinned
+	case model.BatchColor:
+		if operation.Color == nil {
+			return item, errors.New("color is required")
+		}
+		if *operation.Color != "" && !utils.IsHexColor(*operation.Color) {
+			return item, errors.New("color must look like #rrggbb")
+		}
+		item.Color = *operation.Color
+	case model.BatchLabel, model.BatchUnlabel:
+		labelId, err := primitive.ObjectIDFromHex(operation.LabelId)
+		if err != nil {
+			return item, errors.New("label_id is required")
+		}
+		item.LabelId = labelId
+	default:
+		return item, errors.New("unknown op " + strconv.Quote(operation.Op))
+	}
+
+	return item, nil
+}
+
+func (r *NoteServiceImpl) SetPinned(userId primitive.ObjectID, bookId primitive.ObjectID, noteId primitive.ObjectID, pinned bool) (*model.NoteResponse, error) {
+	return r.noteRepo.SetPinned(userId, bookId, noteId, pinned)
+}
+
+func (r *NoteServiceImpl) ReorderNote(reorder *model.NoteReorder) (*model.NoteResponse, error) {
+	if reorder.AfterId == nil && reorder.BeforeId == nil {
+		return nil, errors.New("after_id or before_id is required")
+	}
+
+	for _, neighbour := range []*primitive.ObjectID{reorder.AfterId, reorder.BeforeId} {
+		if neighbour != nil && *neighbour == reorder.ID {
+			return nil, errors.New("a note cannot be placed next to itself")
+		}
+	}
+
+	if reorder.AfterId != nil && reorder.BeforeId != nil && *reorder.AfterId == *reorder.BeforeId {
+		return nil, errors.New("after_id and before_id must differ")
+	}
+
+	return r.noteRepo.ReorderNote(reorder)
+}
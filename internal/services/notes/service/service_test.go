@@ -0,0 +1,242 @@
+package service
+
+import (
+	model "noto/internal/services/notes/model"
+	"noto/internal/services/notes/repository"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type stubNoteRepo struct {
+	repository.NoteRepository
+	transfer *model.NoteTransfer
+	items    []model.NoteBatchItem
+	reorder  *model.NoteReorder
+}
+
+func (r *stubNoteRepo) CreateNote(note *model.NoteCreate) (*model.NoteCreate, error) {
+	return note, nil
+}
+
+func (r *stubNoteRepo) ReorderNote(reorder *model.NoteReorder) (*model.NoteResponse, error) {
+	r.reorder = reorder
+	return &model.NoteResponse{ID: reorder.ID.Hex()}, nil
+}
+
+func (r *stubNoteRepo) BatchNotes(userId primitive.ObjectID, bookId primitive.ObjectID, items []model.NoteBatchItem) ([]model.NoteBatchResult, error) {
+	r.items = items
+	results := make([]model.NoteBatchResult, len(items))
+	for i, item := range items {
+		results[i] = model.NoteBatchResult{Op: item.Op, NoteId: item.NoteId.Hex(), Success: true}
+	}
+	return results, nil
+}
+
+func (r *stubNoteRepo) MoveNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	r.transfer = transfer
+	return []model.NoteResponse{}, nil
+}
+
+func (r *stubNoteRepo) CopyNotes(transfer *model.NoteTransfer) ([]model.NoteResponse, error) {
+	r.transfer = transfer
+	return []model.NoteResponse{}, nil
+}
+
+func TestMoveNotes(t *testing.T) {
+	bookId := primitive.NewObjectID()
+	noteId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name          string
+		transfer      model.NoteTransfer
+		expectedIds   []primitive.ObjectID
+		expectedError string
+	}{
+		{
+			name:        "Removes Duplicates",
+			transfer:    model.NoteTransfer{FromBookId: bookId, BookId: primitive.NewObjectID(), NoteIds: []primitive.ObjectID{noteId, noteId}},
+			expectedIds: []primitive.ObjectID{noteId},
+		},
+		{
+			name:          "Missing Notes",
+			transfer:      model.NoteTransfer{FromBookId: bookId, BookId: primitive.NewObjectID()},
+			expectedError: "note_ids is required",
+		},
+		{
+			name:          "Missing Target",
+			transfer:      model.NoteTransfer{FromBookId: bookId, NoteIds: []primitive.ObjectID{noteId}},
+			expectedError: "book_id is required",
+		},
+		{
+			name:          "Same Book",
+			transfer:      model.NoteTransfer{FromBookId: bookId, BookId: bookId, NoteIds: []primitive.ObjectID{noteId}},
+			expectedError: "notes are already in this book",
+		},
+		{
+			name:          "Too Many Notes",
+			transfer:      model.NoteTransfer{FromBookId: bookId, BookId: primitive.NewObjectID(), NoteIds: manyIds(MaxBulkNotes + 1)},
+			expectedError: "at most 500 notes can be changed at once",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubNoteRepo{}
+			serv := NewNoteService(repo, 0)
+
+			_, err := serv.MoveNotes(&testCase.transfer)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, repo.transfer)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedIds, repo.transfer.NoteIds)
+		})
+	}
+}
+
+func TestCopyNotesSameBook(t *testing.T) {
+	bookId := primitive.NewObjectID()
+	repo := &stubNoteRepo{}
+	serv := NewNoteService(repo, 0)
+
+	_, err := serv.CopyNotes(&model.NoteTransfer{FromBookId: bookId, BookId: bookId, NoteIds: manyIds(2)})
+	require.NoError(t, err)
+	assert.Len(t, repo.transfer.NoteIds, 2)
+}
+
+func TestBatchNotes(t *testing.T) {
+	noteId := primitive.NewObjectID()
+	pinned := false
+	color := "#ffcc00"
+	badColor := "red"
+
+	testCases := []struct {
+		name            string
+		batch           model.NoteBatch
+		expectedItems   []model.NoteBatchItem
+		expectedResults []model.NoteBatchResult
+		expectedError   string
+	}{
+		{
+			name: "Expands Operations",
+			batch: model.NoteBatch{Operations: []model.NoteBatchOperation{
+				{Op: model.BatchPin, NoteIds: []string{noteId.Hex()}},
+				{Op: model.BatchPin, NoteIds: []string{noteId.Hex()}, Pinned: &pinned},
+				{Op: model.BatchColor, NoteIds: []string{"bad", noteId.Hex()}, Color: &color},
+			}},
+			expectedItems: []model.NoteBatchItem{
+				{Op: model.BatchPin, NoteId: noteId, Pinned: true},
+				{Op: model.BatchPin, NoteId: noteId, Pinned: false},
+				{Op: model.BatchColor, NoteId: noteId, Color: color},
+			},
+			expectedResults: []model.NoteBatchResult{
+				{Op: model.BatchPin, NoteId: noteId.Hex(), Success: true},
+				{Op: model.BatchPin, NoteId: noteId.Hex(), Success: true},
+				{Op: model.BatchColor, NoteId: "bad", Error: "invalid id format"},
+				{Op: model.BatchColor, NoteId: noteId.Hex(), Success: true},
+			},
+		},
+		{
+			name:          "No Operations",
+			batch:         model.NoteBatch{},
+			expectedError: "operations is required",
+		},
+		{
+			name:          "Unknown Op",
+			batch:         model.NoteBatch{Operations: []model.NoteBatchOperation{{Op: "archive", NoteIds: []string{noteId.Hex()}}}},
+			expectedError: `invalid operation 1: unknown op "archive"`,
+		},
+		{
+			name:          "Move Without Book",
+			batch:         model.NoteBatch{Operations: []model.NoteBatchOperation{{Op: model.BatchMove, NoteIds: []string{noteId.Hex()}}}},
+			expectedError: "invalid operation 1: book_id is required",
+		},
+		{
+			name:          "Invalid Color",
+			batch:         model.NoteBatch{Operations: []model.NoteBatchOperation{{Op: model.BatchColor, NoteIds: []string{noteId.Hex()}, Color: &badColor}}},
+			expectedError: "invalid operation 1: color must look like #rrggbb",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubNoteRepo{}
+			serv := NewNoteService(repo, 0)
+
+			response, err := serv.BatchNotes(primitive.NewObjectID(), primitive.NewObjectID(), &testCase.batch)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, repo.items)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expectedItems, repo.items)
+			assert.Equal(t, testCase.expectedResults, response.Results)
+		})
+	}
+}
+
+func TestReorderNote(t *testing.T) {
+	noteId := primitive.NewObjectID()
+	otherId := primitive.NewObjectID()
+
+	testCases := []struct {
+		name          string
+		afterId       *primitive.ObjectID
+		beforeId      *primitive.ObjectID
+		expectedError string
+	}{
+		{name: "After Only", afterId: &otherId},
+		{name: "Before Only", beforeId: &otherId},
+		{name: "No Neighbours", expectedError: "after_id or before_id is required"},
+		{name: "Next To Itself", afterId: &noteId, expectedError: "a note cannot be placed next to itself"},
+		{name: "Same Neighbours", afterId: &otherId, beforeId: &otherId, expectedError: "after_id and before_id must differ"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			repo := &stubNoteRepo{}
+			serv := NewNoteService(repo, 0)
+
+			reorder := &model.NoteReorder{ID: noteId, AfterId: testCase.afterId, BeforeId: testCase.beforeId}
+			_, err := serv.ReorderNote(reorder)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				assert.Nil(t, repo.reorder)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, reorder, repo.reorder)
+		})
+	}
+}
+
+func manyIds(n int) []primitive.ObjectID {
+	ids := make([]primitive.ObjectID, n)
+	for i := range ids {
+		ids[i] = primitive.NewObjectID()
+	}
+	return ids
+}
+
+func TestCreateNoteColor(t *testing.T) {
+	serv := NewNoteService(&stubNoteRepo{}, 0)
+
+	_, err := serv.CreateNote(&model.NoteCreate{Text: "note", Color: "red"})
+	assert.EqualError(t, err, "color must look like #rrggbb")
+
+	note, err := serv.CreateNote(&model.NoteCreate{Text: "note", Color: "#ffcc00"})
+	require.NoError(t, err)
+	assert.Equal(t, "#ffcc00", note.Color)
+
+	_, err = serv.CreateNote(&model.NoteCreate{Text: "note"})
+	assert.NoError(t, err, "color is optional")
+}
@@ -0,0 +1,65 @@
+package handler
+
+import (
+	_ "noto/internal/common"
+	_ "noto/internal/services/search/model"
+	"noto/internal/services/search/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type SearchHandler interface {
+	Search(c *fiber.Ctx) error
+}
+
+type SearchHandlerImpl struct {
+	searchService service.SearchService
+}
+
+func NewSearchHandler(searchService service.SearchService) SearchHandler {
+	return &SearchHandlerImpl{searchService: searchService}
+}
+
+// Search
+// @Summary		Search notes and books
+// @Description	Full-text search over note text and book titles, ranked by relevance. Matches in the snippet are wrapped in <mark>.
+// @Description	Supports quoted phrases and excluding words with a leading minus.
+// @Tags		Search
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		q			query		string	true	"Search query"
+// @Param		page		query		int		false	"Page number for pagination"	minimum(1)
+// @Param		limit		query		int		false	"Number of items per page"	minimum(1)
+// @Success		200		{object}	model.PaginatedSearchResponse
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/search [get]
+func (s *SearchHandlerImpl) Search(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+	if page < 1 {
+		return utils.ErrorBadRequest(c, "page must be at least 1")
+	}
+	if limit < 1 {
+		return utils.ErrorBadRequest(c, "limit must be at least 1")
+	}
+
+	results, err := s.searchService.Search(userId, c.Query("q"), page, limit)
+	if err != nil {
+		if err.Error() == "query is required" {
+			return utils.ErrorBadRequest(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(results)
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type SearchBook struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	Title      string             `json:"title" bson:"title"`
+	IsArchived bool               `json:"is_archived" bson:"isArchived"`
+}
+
+type SearchResult struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Type      string             `json:"type" bson:"type" example:"note"`
+	Score     float64            `json:"score" bson:"score"`
+	Snippet   string             `json:"snippet" bson:"-" example:"a note about <mark>mongodb</mark> indexes"`
+	Text      string             `json:"-" bson:"text"`
+	Book      SearchBook         `json:"book" bson:"book"`
+	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+}
+
+type PaginationMetadata struct {
+	TotalData    int  `json:"totalData" bson:"totalData"`
+	TotalPage    int  `json:"totalPage" bson:"totalPage"`
+	PreviousPage *int `json:"previousPage" bson:"previousPage"`
+	CurrentPage  int  `json:"currentPage" bson:"currentPage"`
+	NextPage     *int `json:"nextPage" bson:"nextPage"`
+}
+
+type PaginatedSearchResponse struct {
+	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
+	Data     []SearchResult     `json:"data" bson:"data"`
+}
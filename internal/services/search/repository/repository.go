@@ -0,0 +1,117 @@
+package repository
+
+import (
+	"context"
+	"noto/internal/services/search/model"
+	"noto/internal/utils"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type SearchRepository interface {
+	Search(userId primitive.ObjectID, query string, page int, limit int) (*model.PaginatedSearchResponse, error)
+	EnsureIndexes() error
+}
+
+type SearchRepositoryImpl struct {
+	notes *mongo.Collection
+	books *mongo.Collection
+}
+
+func NewSearchRepository(db *mongo.Database) SearchRepository {
+	return &SearchRepositoryImpl{notes: db.Collection("notes"), books: db.Collection("books")}
+}
+
+// EnsureIndexes creates the text indexes searched by Search. MongoDB allows a
+// single text index per collection, so these must stay the only ones.
+func (r *SearchRepositoryImpl) EnsureIndexes() error {
+	_, err := r.notes.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "text", Value: "text"}},
+		Options: options.Index().SetName("notes_text"),
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = r.books.Indexes().CreateOne(context.Background(), mongo.IndexModel{
+		Keys:    bson.D{{Key: "title", Value: "text"}},
+		Options: options.Index().SetName("books_text"),
+	})
+
+	return err
+}
+
+func (r *SearchRepositoryImpl) Search(userId primitive.ObjectID, query string, page int, limit int) (*model.PaginatedSearchResponse, error) {
+	var results []model.PaginatedSearchResponse
+
+	match := bson.M{
+		"$text":     bson.M{"$search": query},
+		"userId":    userId,
+		"deletedAt": nil,
+	}
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "books",
+			"localField":   "bookId",
+			"foreignField": "_id",
+			"as":           "book",
+		}}},
+		{{Key: "$unwind", Value: "$book"}},
+		// Notes of a trashed book are hidden along with it.
+		{{Key: "$match", Value: bson.M{"book.deletedAt": nil}}},
+		{{Key: "$project", Value: bson.M{
+			"type":            "note",
+			"score":           bson.M{"$meta": "textScore"},
+			"text":            1,
+			"book._id":        1,
+			"book.title":      1,
+			"book.isArchived": 1,
+			"createdAt":       1,
+			"updatedAt":       1,
+		}}},
+		{{Key: "$unionWith", Value: bson.M{
+			"coll": "books",
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: match}},
+				{{Key: "$project", Value: bson.M{
+					"type":  "book",
+					"score": bson.M{"$meta": "textScore"},
+					"text":  "$title",
+					"book": bson.M{
+						"_id":        "$_id",
+						"title":      "$title",
+						"isArchived": "$isArchived",
+					},
+					"createdAt": 1,
+					"updatedAt": 1,
+				}}},
+			},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "score", Value: -1}, {Key: "updatedAt", Value: -1}}}},
+		{{Key: "$facet", Value: utils.PaginationAggregate(page, limit)}},
+		{{Key: "$unwind", Value: "$metadata"}},
+	}
+
+	cursor, err := r.notes.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &results); err != nil {
+		return nil, err
+	}
+
+	if len(results) == 0 {
+		return &model.PaginatedSearchResponse{
+			Data:     []model.SearchResult{},
+			Metadata: model.PaginationMetadata{},
+		}, nil
+	}
+
+	return &results[0], nil
+}
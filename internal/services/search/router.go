@@ -0,0 +1,25 @@
+package search_router
+
+import (
+	"log"
+	"noto/internal/config"
+	"noto/internal/middleware"
+	"noto/internal/services/search/handler"
+	"noto/internal/services/search/repository"
+	"noto/internal/services/search/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func SearchRouter(router fiber.Router) {
+	var repo = repository.NewSearchRepository(config.DB)
+	var serv = service.NewSearchService(repo)
+	var hand = handler.NewSearchHandler(serv)
+
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create search indexes:", err)
+	}
+
+	router.Get("/search", middleware.RequireScope(utils.ScopeBooksRead, utils.ScopeNotesRead), hand.Search)
+}
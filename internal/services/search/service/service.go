@@ -0,0 +1,161 @@
+package service
+
+import (
+	"errors"
+	"html"
+	"noto/internal/services/search/model"
+	"noto/internal/services/search/repository"
+	"strings"
+	"unicode"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	snippetLength  = 160
+	snippetContext = 60
+)
+
+type SearchService interface {
+	Search(userId primitive.ObjectID, query string, page int, limit int) (*model.PaginatedSearchResponse, error)
+}
+
+type SearchServiceImpl struct {
+	searchRepo repository.SearchRepository
+}
+
+func NewSearchService(searchRepo repository.SearchRepository) SearchService {
+	return &SearchServiceImpl{searchRepo: searchRepo}
+}
+
+func (s *SearchServiceImpl) Search(userId primitive.ObjectID, query string, page int, limit int) (*model.PaginatedSearchResponse, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, errors.New("query is required")
+	}
+
+	results, err := s.searchRepo.Search(userId, query, page, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	terms := searchTerms(query)
+	for i := range results.Data {
+		results.Data[i].Snippet = snippet(results.Data[i].Text, terms)
+	}
+
+	return results, nil
+}
+
+// searchTerms extracts the words and quoted phrases of a $text query,
+// skipping negated ones since those never appear in a hit.
+func searchTerms(query string) []string {
+	terms := []string{}
+	for i, part := range strings.Split(query, `"`) {
+		if i%2 == 1 {
+			if phrase := strings.TrimSpace(part); phrase != "" {
+				terms = append(terms, strings.ToLower(phrase))
+			}
+			continue
+		}
+
+		for _, word := range strings.Fields(part) {
+			if strings.HasPrefix(word, "-") {
+				continue
+			}
+			terms = append(terms, strings.ToLower(word))
+		}
+	}
+
+	return terms
+}
+
+// snippet returns an excerpt of text around the first matching term with
+// every match wrapped in <mark>. The rest of the text is HTML escaped.
+func snippet(text string, terms []string) string {
+	runes := []rune(strings.Join(strings.Fields(text), " "))
+	lower := make([]rune, len(runes))
+	for i, r := range runes {
+		lower[i] = unicode.ToLower(r)
+	}
+
+	first := -1
+	for _, term := range terms {
+		if idx := runeIndex(lower, []rune(term)); idx >= 0 && (first < 0 || idx < first) {
+			first = idx
+		}
+	}
+
+	start := 0
+	if first > snippetContext {
+		start = first - snippetContext
+		for start < first && !unicode.IsSpace(runes[start]) {
+			start++
+		}
+	}
+
+	end := start + snippetLength
+	if end >= len(runes) {
+		end = len(runes)
+	} else {
+		for end > start && !unicode.IsSpace(runes[end]) {
+			end--
+		}
+		if end == start {
+			end = start + snippetLength
+		}
+	}
+
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString("…")
+	}
+
+	for i := start; i < end; {
+		length := 0
+		for _, term := range terms {
+			termRunes := []rune(term)
+			if len(termRunes) > length && i+len(termRunes) <= end && runeIndex(lower[i:i+len(termRunes)], termRunes) == 0 {
+				length = len(termRunes)
+			}
+		}
+
+		if length == 0 {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		b.WriteString("<mark>")
+		b.WriteString(html.EscapeString(string(runes[i : i+length])))
+		b.WriteString("</mark>")
+		i += length
+	}
+
+	if end < len(runes) {
+		b.WriteString("…")
+	}
+
+	return strings.TrimSpace(b.String())
+}
+
+func runeIndex(s []rune, sub []rune) int {
+	if len(sub) == 0 {
+		return -1
+	}
+
+	for i := 0; i+len(sub) <= len(s); i++ {
+		match := true
+		for j := range sub {
+			if s[i+j] != sub[j] {
+				match = false
+				break
+			}
+		}
+		if match {
+			return i
+		}
+	}
+
+	return -1
+}
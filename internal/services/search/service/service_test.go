@@ -0,0 +1,78 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSearchTerms(t *testing.T) {
+	testCases := []struct {
+		name     string
+		query    string
+		expected []string
+	}{
+		{name: "Words", query: "Mongo  Index", expected: []string{"mongo", "index"}},
+		{name: "Phrase", query: `"text index" go`, expected: []string{"text index", "go"}},
+		{name: "Negation", query: "go -java", expected: []string{"go"}},
+		{name: "Empty", query: "", expected: []string{}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, searchTerms(testCase.query))
+		})
+	}
+}
+
+func TestSnippet(t *testing.T) {
+	long := strings.Repeat("lorem ipsum ", 20) + "the Mongo index is here " + strings.Repeat("dolor sit ", 20)
+
+	testCases := []struct {
+		name     string
+		text     string
+		terms    []string
+		expected string
+	}{
+		{
+			name:     "Short Text",
+			text:     "Notes about MongoDB",
+			terms:    []string{"mongodb"},
+			expected: "Notes about <mark>MongoDB</mark>",
+		},
+		{
+			name:     "Escapes HTML",
+			text:     "<b>go</b> & rust",
+			terms:    []string{"go"},
+			expected: "&lt;b&gt;<mark>go</mark>&lt;/b&gt; &amp; rust",
+		},
+		{
+			name:     "Longest Term Wins",
+			text:     "text index",
+			terms:    []string{"text", "text index"},
+			expected: "<mark>text index</mark>",
+		},
+		{
+			name:     "No Match",
+			text:     "running fast",
+			terms:    []string{"run fast"},
+			expected: "running fast",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, snippet(testCase.text, testCase.terms))
+		})
+	}
+
+	t.Run("Long Text", func(t *testing.T) {
+		snippet := snippet(long, []string{"mongo"})
+
+		assert.True(t, strings.HasPrefix(snippet, "…"))
+		assert.True(t, strings.HasSuffix(snippet, "…"))
+		assert.Contains(t, snippet, "<mark>Mongo</mark> index")
+		assert.LessOrEqual(t, len([]rune(snippet)), snippetLength+len("<mark></mark>")+2)
+	})
+}
@@ -0,0 +1,136 @@
+package handler
+
+import (
+	_ "noto/internal/common"
+	"noto/internal/services/tokens/model"
+	"noto/internal/services/tokens/service"
+	"noto/internal/utils"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TokenHandler interface {
+	CreateToken(c *fiber.Ctx) error
+	GetTokens(c *fiber.Ctx) error
+	DeleteToken(c *fiber.Ctx) error
+}
+
+type TokenHandlerImpl struct {
+	tokenService service.TokenService
+}
+
+func NewTokenHandler(tokenService service.TokenService) TokenHandler {
+	return &TokenHandlerImpl{tokenService: tokenService}
+}
+
+// CreateToken
+// @Summary		Create a personal access token
+// @Description	Create a named long-lived token for scripts and integrations. The token is only shown once.
+// @Description	Scopes limit what the token can do, e.g. books:read, notes:write or book:<id>. Without scopes the token has full access.
+// @Tags		Tokens
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		token	body		model.TokenCreateSwagger	true	"Token to create"
+// @Success		201		{object}	model.TokenCreated
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     403     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/tokens [post]
+func (s *TokenHandlerImpl) CreateToken(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	request := new(model.TokenCreateRequest)
+	if err := c.BodyParser(request); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	token := &model.TokenCreate{
+		UserId:    userId,
+		Name:      request.Name,
+		Scopes:    request.Scopes,
+		ExpiresAt: request.ExpiresAt,
+	}
+	callerScopes, _ := c.Locals("scopes").([]string)
+	newToken, err := s.tokenService.CreateToken(token, callerScopes)
+	if err != nil {
+		switch {
+		case err.Error() == "name is required", err.Error() == "expires_at must be in the future",
+			strings.HasPrefix(err.Error(), "invalid scope"):
+			return utils.ErrorBadRequest(c, err.Error())
+		case err.Error() == "scopes exceed those of the current token":
+			return utils.ErrorForbidden(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to create token: "+err.Error())
+	}
+
+	return c.Status(fiber.StatusCreated).JSON(newToken)
+}
+
+// GetTokens
+// @Summary		Get all personal access tokens
+// @Description	Get all personal access tokens of the current user
+// @Tags		Tokens
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Success		200		{object}	[]model.TokenResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/tokens [get]
+func (s *TokenHandlerImpl) GetTokens(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	tokens, err := s.tokenService.GetTokens(userId)
+	if err != nil {
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(tokens)
+}
+
+// DeleteToken
+// @Summary		Revoke personal access token
+// @Description	Revoke personal access token
+// @Tags		Tokens
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		tokenId path string true "Token ID"
+// @Success		200		{object} 	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/tokens/{tokenId} [delete]
+func (s *TokenHandlerImpl) DeleteToken(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	tokenId, err := utils.ToObjectID(c.Params("tokenId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.tokenService.DeleteToken(userId, tokenId); err != nil {
+		if err.Error() == "token not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to revoke token: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "token revoked",
+	})
+}
@@ -0,0 +1,51 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TokenCreateSwagger struct {
+	Name      string     `json:"name" bson:"name"`
+	Scopes    []string   `json:"scopes" bson:"scopes" example:"books:read,book:507f1f77bcf86cd799439011"`
+	ExpiresAt *time.Time `json:"expires_at" bson:"expiresAt"`
+}
+
+// TokenCreateRequest holds the fields a client may set when creating a token.
+// Everything else on TokenCreate is set by the server.
+type TokenCreateRequest struct {
+	Name      string     `json:"name"`
+	Scopes    []string   `json:"scopes"`
+	ExpiresAt *time.Time `json:"expires_at"`
+}
+
+type TokenCreate struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id,omitempty"`
+	UserId     primitive.ObjectID `json:"user_id" bson:"userId"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"`
+	Scopes     []string           `json:"scopes" bson:"scopes,omitempty"`
+	TokenHash  string             `json:"-" bson:"tokenHash"`
+	ExpiresAt  *time.Time         `json:"expires_at" bson:"expiresAt"`
+	LastUsedAt *time.Time         `json:"last_used_at" bson:"lastUsedAt"`
+	CreatedAt  time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updatedAt"`
+}
+
+type TokenCreated struct {
+	TokenResponse
+	Token string `json:"token"`
+}
+
+type TokenResponse struct {
+	ID         primitive.ObjectID `json:"id" bson:"_id"`
+	UserId     primitive.ObjectID `json:"-" bson:"userId"`
+	Name       string             `json:"name" bson:"name"`
+	Prefix     string             `json:"prefix" bson:"prefix"`
+	Scopes     []string           `json:"scopes" bson:"scopes"`
+	ExpiresAt  *time.Time         `json:"expires_at" bson:"expiresAt"`
+	LastUsedAt *time.Time         `json:"last_used_at" bson:"lastUsedAt"`
+	CreatedAt  time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt  time.Time          `json:"updated_at" bson:"updatedAt"`
+}
@@ -0,0 +1,123 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"noto/internal/services/tokens/model"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TokenRepository interface {
+	CreateToken(token *model.TokenCreate) (*model.TokenCreate, error)
+	GetTokens(userId primitive.ObjectID) ([]model.TokenResponse, error)
+	DeleteToken(userId primitive.ObjectID, tokenId primitive.ObjectID) error
+	FindActiveToken(tokenHash string) (*model.TokenResponse, error)
+	TouchToken(tokenId primitive.ObjectID) error
+	EnsureIndexes() error
+}
+
+type TokenRepositoryImpl struct {
+	tokens *mongo.Collection
+}
+
+func NewTokenRepository(db *mongo.Database) TokenRepository {
+	return &TokenRepositoryImpl{tokens: db.Collection("personal_tokens")}
+}
+
+func (r *TokenRepositoryImpl) EnsureIndexes() error {
+	_, err := r.tokens.Indexes().CreateMany(context.Background(), []mongo.IndexModel{
+		{Keys: bson.D{{Key: "tokenHash", Value: 1}}, Options: options.Index().SetUnique(true)},
+		{Keys: bson.D{{Key: "userId", Value: 1}}},
+	})
+
+	return err
+}
+
+func (r *TokenRepositoryImpl) CreateToken(token *model.TokenCreate) (*model.TokenCreate, error) {
+	token.CreatedAt = time.Now()
+	token.UpdatedAt = time.Now()
+
+	newToken, err := r.tokens.InsertOne(context.Background(), token)
+	if err != nil {
+		return nil, err
+	}
+
+	token.ID = newToken.InsertedID.(primitive.ObjectID)
+
+	return token, nil
+}
+
+func (r *TokenRepositoryImpl) GetTokens(userId primitive.ObjectID) ([]model.TokenResponse, error) {
+	var tokens []model.TokenResponse
+
+	opts := options.Find().SetSort(bson.M{"createdAt": -1})
+	cursor, err := r.tokens.Find(context.Background(), bson.M{"userId": userId}, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &tokens); err != nil {
+		return nil, err
+	}
+
+	if len(tokens) == 0 {
+		return []model.TokenResponse{}, nil
+	}
+
+	return tokens, nil
+}
+
+func (r *TokenRepositoryImpl) DeleteToken(userId primitive.ObjectID, tokenId primitive.ObjectID) error {
+	filter := bson.M{"_id": tokenId, "userId": userId}
+	deleted, err := r.tokens.DeleteOne(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+
+	if deleted.DeletedCount == 0 {
+		return errors.New("token not found")
+	}
+
+	return nil
+}
+
+func (r *TokenRepositoryImpl) FindActiveToken(tokenHash string) (*model.TokenResponse, error) {
+	filter := bson.M{
+		"tokenHash": tokenHash,
+		"$or": []bson.M{
+			{"expiresAt": nil},
+			{"expiresAt": bson.M{"$gt": time.Now()}},
+		},
+	}
+
+	var token model.TokenResponse
+	err := r.tokens.FindOne(context.Background(), filter).Decode(&token)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("token not found")
+		}
+		return nil, err
+	}
+
+	return &token, nil
+}
+
+func (r *TokenRepositoryImpl) TouchToken(tokenId primitive.ObjectID) error {
+	now := time.Now()
+	filter := bson.M{
+		"_id": tokenId,
+		"$or": []bson.M{
+			{"lastUsedAt": nil},
+			{"lastUsedAt": bson.M{"$lt": now.Add(-time.Minute)}},
+		},
+	}
+	update := bson.M{"$set": bson.M{"lastUsedAt": now}}
+
+	_, err := r.tokens.UpdateOne(context.Background(), filter, update)
+	return err
+}
@@ -0,0 +1,30 @@
+package tokens_router
+
+import (
+	"log"
+	"noto/internal/config"
+	"noto/internal/middleware"
+	"noto/internal/services/tokens/handler"
+	"noto/internal/services/tokens/repository"
+	"noto/internal/services/tokens/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func TokensRouter(router fiber.Router) {
+	var repo = repository.NewTokenRepository(config.DB)
+	var serv = service.NewTokenService(repo)
+	var hand = handler.NewTokenHandler(serv)
+
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create token indexes:", err)
+	}
+
+	var read = middleware.RequireScope(utils.ScopeTokensRead)
+	var write = middleware.RequireScope(utils.ScopeTokensWrite)
+
+	router.Post("/tokens", write, hand.CreateToken)
+	router.Get("/tokens", read, hand.GetTokens)
+	router.Delete("/tokens/:tokenId", write, hand.DeleteToken)
+}
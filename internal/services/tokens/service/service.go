@@ -0,0 +1,106 @@
+package service
+
+import (
+	"errors"
+	"noto/internal/services/tokens/model"
+	"noto/internal/services/tokens/repository"
+	"noto/internal/utils"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const TokenPrefix = "noto_pat_"
+
+type TokenService interface {
+	CreateToken(token *model.TokenCreate, callerScopes []string) (*model.TokenCreated, error)
+	GetTokens(userId primitive.ObjectID) ([]model.TokenResponse, error)
+	DeleteToken(userId primitive.ObjectID, tokenId primitive.ObjectID) error
+	Authenticate(rawToken string) (*model.TokenResponse, error)
+}
+
+type TokenServiceImpl struct {
+	tokenRepo repository.TokenRepository
+}
+
+func NewTokenService(tokenRepo repository.TokenRepository) TokenService {
+	return &TokenServiceImpl{tokenRepo: tokenRepo}
+}
+
+func IsPersonalToken(rawToken string) bool {
+	return strings.HasPrefix(rawToken, TokenPrefix)
+}
+
+func (s *TokenServiceImpl) CreateToken(token *model.TokenCreate, callerScopes []string) (*model.TokenCreated, error) {
+	token.Name = strings.TrimSpace(token.Name)
+	if token.Name == "" {
+		return nil, errors.New("name is required")
+	}
+
+	if err := utils.ValidateScopes(token.Scopes); err != nil {
+		return nil, err
+	}
+
+	if callerScopes != nil && !utils.ScopesSubset(callerScopes, token.Scopes) {
+		return nil, errors.New("scopes exceed those of the current token")
+	}
+
+	if token.ExpiresAt != nil && !token.ExpiresAt.After(time.Now()) {
+		return nil, errors.New("expires_at must be in the future")
+	}
+
+	secret, err := utils.GenerateRandomToken(32)
+	if err != nil {
+		return nil, err
+	}
+
+	rawToken := TokenPrefix + secret
+	token.TokenHash = utils.HashToken(rawToken)
+	token.Prefix = rawToken[:len(TokenPrefix)+4]
+	token.LastUsedAt = nil
+
+	newToken, err := s.tokenRepo.CreateToken(token)
+	if err != nil {
+		return nil, err
+	}
+
+	return &model.TokenCreated{
+		TokenResponse: model.TokenResponse{
+			ID:        newToken.ID,
+			UserId:    newToken.UserId,
+			Name:      newToken.Name,
+			Prefix:    newToken.Prefix,
+			Scopes:    newToken.Scopes,
+			ExpiresAt: newToken.ExpiresAt,
+			CreatedAt: newToken.CreatedAt,
+			UpdatedAt: newToken.UpdatedAt,
+		},
+		Token: rawToken,
+	}, nil
+}
+
+func (s *TokenServiceImpl) GetTokens(userId primitive.ObjectID) ([]model.TokenResponse, error) {
+	return s.tokenRepo.GetTokens(userId)
+}
+
+func (s *TokenServiceImpl) DeleteToken(userId primitive.ObjectID, tokenId primitive.ObjectID) error {
+	return s.tokenRepo.DeleteToken(userId, tokenId)
+}
+
+func (s *TokenServiceImpl) Authenticate(rawToken string) (*model.TokenResponse, error) {
+	if !IsPersonalToken(rawToken) {
+		return nil, errors.New("token not found")
+	}
+
+	token, err := s.tokenRepo.FindActiveToken(utils.HashToken(rawToken))
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.tokenRepo.TouchToken(token.ID); err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
@@ -0,0 +1,208 @@
+package handler
+
+import (
+	_ "noto/internal/common"
+	_ "noto/internal/services/trash/model"
+	"noto/internal/services/trash/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type TrashHandler interface {
+	GetTrash(c *fiber.Ctx) error
+	RestoreBook(c *fiber.Ctx) error
+	RestoreNote(c *fiber.Ctx) error
+	DeleteBook(c *fiber.Ctx) error
+	DeleteNote(c *fiber.Ctx) error
+}
+
+type TrashHandlerImpl struct {
+	trashService service.TrashService
+}
+
+func NewTrashHandler(trashService service.TrashService) TrashHandler {
+	return &TrashHandlerImpl{trashService: trashService}
+}
+
+// GetTrash
+// @Summary		Get trash
+// @Description	Get deleted books and notes, most recently deleted first
+// @Tags		Trash
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		page		query		int		false	"Page number for pagination"	minimum(1)
+// @Param		limit		query		int		false	"Number of items per page"	minimum(1)
+// @Success		200		{object}	model.PaginatedTrashResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/trash [get]
+func (s *TrashHandlerImpl) GetTrash(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	page := c.QueryInt("page", 1)
+	limit := c.QueryInt("limit", 10)
+
+	trash, err := s.trashService.GetTrash(userId, page, limit)
+	if err != nil {
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(trash)
+}
+
+// RestoreBook
+// @Summary		Restore book
+// @Description	Restore a deleted book together with its notes and labels
+// @Tags		Trash
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param 		bookId path string true "Book ID"
+// @Success		200		{object}	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/trash/books/{bookId}/restore [post]
+func (s *TrashHandlerImpl) RestoreBook(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.trashService.RestoreBook(userId, bookId); err != nil {
+		if err.Error() == "book not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to restore book: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "book restored",
+	})
+}
+
+// RestoreNote
+// @Summary		Restore note
+// @Description	Restore a deleted note
+// @Tags		Trash
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param 		noteId path string true "Note ID"
+// @Success		200		{object}	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     409     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/trash/notes/{noteId}/restore [post]
+func (s *TrashHandlerImpl) RestoreNote(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.trashService.RestoreNote(userId, noteId); err != nil {
+		switch err.Error() {
+		case "note not found":
+			return utils.ErrorNotFound(c, err.Error())
+		case "book is in the trash":
+			return utils.ErrorConflict(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to restore note: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "note restored",
+	})
+}
+
+// DeleteBook
+// @Summary		Permanently delete book
+// @Description	Permanently delete a book in the trash together with its notes and labels
+// @Tags		Trash
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param 		bookId path string true "Book ID"
+// @Success		200		{object}	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/trash/books/{bookId} [delete]
+func (s *TrashHandlerImpl) DeleteBook(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	bookId, err := utils.ToObjectID(c.Params("bookId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.trashService.DeleteBook(userId, bookId); err != nil {
+		if err.Error() == "book not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to delete book: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "book deleted",
+	})
+}
+
+// DeleteNote
+// @Summary		Permanently delete note
+// @Description	Permanently delete a note in the trash
+// @Tags		Trash
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param 		noteId path string true "Note ID"
+// @Success		200		{object}	interface{}
+// @Failure     400    	{object}   	common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/trash/notes/{noteId} [delete]
+func (s *TrashHandlerImpl) DeleteNote(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	noteId, err := utils.ToObjectID(c.Params("noteId"))
+	if err != nil {
+		return utils.ErrorBadRequest(c, err.Error())
+	}
+
+	if err := s.trashService.DeleteNote(userId, noteId); err != nil {
+		if err.Error() == "note not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to delete note: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "note deleted",
+	})
+}
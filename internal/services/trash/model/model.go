@@ -0,0 +1,31 @@
+package model
+
+import (
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TrashItem struct {
+	ID        primitive.ObjectID `json:"id" bson:"_id"`
+	Type      string             `json:"type" bson:"type" example:"note"`
+	Title     string             `json:"title,omitempty" bson:"title,omitempty"`
+	Text      string             `json:"text,omitempty" bson:"text,omitempty"`
+	BookId    primitive.ObjectID `json:"book_id" bson:"bookId"`
+	CreatedAt time.Time          `json:"created_at" bson:"createdAt"`
+	UpdatedAt time.Time          `json:"updated_at" bson:"updatedAt"`
+	DeletedAt time.Time          `json:"deleted_at" bson:"deletedAt"`
+}
+
+type PaginationMetadata struct {
+	TotalData    int  `json:"totalData" bson:"totalData"`
+	TotalPage    int  `json:"totalPage" bson:"totalPage"`
+	PreviousPage *int `json:"previousPage" bson:"previousPage"`
+	CurrentPage  int  `json:"currentPage" bson:"currentPage"`
+	NextPage     *int `json:"nextPage" bson:"nextPage"`
+}
+
+type PaginatedTrashResponse struct {
+	Metadata PaginationMetadata `json:"metadata" bson:"metadata"`
+	Data     []TrashItem        `json:"data" bson:"data"`
+}
@@ -0,0 +1,351 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"noto/internal/services/trash/model"
+	"noto/internal/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+type TrashRepository interface {
+	GetTrash(userId primitive.ObjectID, page int, limit int) (*model.PaginatedTrashResponse, error)
+	RestoreBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
+	RestoreNote(userId primitive.ObjectID, noteId primitive.ObjectID) error
+	DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
+	DeleteNote(userId primitive.ObjectID, noteId primitive.ObjectID) error
+	Purge(before time.Time) (int64, error)
+	EnsureIndexes() error
+}
+
+type TrashRepositoryImpl struct {
+	db          *mongo.Database
+	books       *mongo.Collection
+	notes       *mongo.Collection
+	revisions   *mongo.Collection
+	note_labels *mongo.Collection
+	book_labels *mongo.Collection
+}
+
+func NewTrashRepository(db *mongo.Database) TrashRepository {
+	return &TrashRepositoryImpl{
+		db:          db,
+		books:       db.Collection("books"),
+		notes:       db.Collection("notes"),
+		revisions:   db.Collection("note_revisions"),
+		note_labels: db.Collection("note_labels"),
+		book_labels: db.Collection("book_labels"),
+	}
+}
+
+func (r *TrashRepositoryImpl) EnsureIndexes() error {
+	index := mongo.IndexModel{
+		Keys:    bson.D{{Key: "deletedAt", Value: 1}},
+		Options: options.Index().SetSparse(true),
+	}
+
+	if _, err := r.books.Indexes().CreateOne(context.Background(), index); err != nil {
+		return err
+	}
+
+	_, err := r.notes.Indexes().CreateOne(context.Background(), index)
+	return err
+}
+
+func (r *TrashRepositoryImpl) GetTrash(userId primitive.ObjectID, page int, limit int) (*model.PaginatedTrashResponse, error) {
+	var trash []model.PaginatedTrashResponse
+
+	match := bson.M{"userId": userId, "deletedAt": bson.M{"$ne": nil}}
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: match}},
+		{{Key: "$project", Value: bson.M{
+			"type":      "note",
+			"text":      1,
+			"bookId":    1,
+			"createdAt": 1,
+			"updatedAt": 1,
+			"deletedAt": 1,
+		}}},
+		{{Key: "$unionWith", Value: bson.M{
+			"coll": "books",
+			"pipeline": mongo.Pipeline{
+				{{Key: "$match", Value: match}},
+				{{Key: "$project", Value: bson.M{
+					"type":      "book",
+					"title":     1,
+					"bookId":    "$_id",
+					"createdAt": 1,
+					"updatedAt": 1,
+					"deletedAt": 1,
+				}}},
+			},
+		}}},
+		{{Key: "$sort", Value: bson.D{{Key: "deletedAt", Value: -1}, {Key: "_id", Value: -1}}}},
+		{{Key: "$facet", Value: utils.PaginationAggregate(page, limit)}},
+		{{Key: "$unwind", Value: "$metadata"}},
+	}
+
+	cursor, err := r.notes.Aggregate(context.Background(), pipeline)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := cursor.All(context.Background(), &trash); err != nil {
+		return nil, err
+	}
+
+	if len(trash) == 0 {
+		return &model.PaginatedTrashResponse{
+			Data:     []model.TrashItem{},
+			Metadata: model.PaginationMetadata{},
+		}, nil
+	}
+
+	return &trash[0], nil
+}
+
+func (r *TrashRepositoryImpl) restore(ctx context.Context, collection *mongo.Collection, userId primitive.ObjectID, id primitive.ObjectID) (bool, error) {
+	filter := bson.M{"_id": id, "userId": userId, "deletedAt": bson.M{"$ne": nil}}
+	update := bson.M{
+		"$unset": bson.M{"deletedAt": ""},
+		"$set":   bson.M{"updatedAt": time.Now()},
+	}
+
+	updated, err := collection.UpdateOne(ctx, filter, update)
+	if err != nil {
+		return false, err
+	}
+
+	return updated.MatchedCount > 0, nil
+}
+
+func (r *TrashRepositoryImpl) RestoreBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	restored, err := r.restore(context.Background(), r.books, userId, bookId)
+	if err != nil {
+		return err
+	}
+
+	if !restored {
+		return errors.New("book not found")
+	}
+
+	return nil
+}
+
+// RestoreNote brings a note back only while its book is not in the trash,
+// since the note would otherwise be restored into a book nobody can see.
+func (r *TrashRepositoryImpl) RestoreNote(userId primitive.ObjectID, noteId primitive.ObjectID) error {
+	return utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		var note struct {
+			BookId primitive.ObjectID `bson:"bookId"`
+		}
+		err := r.notes.FindOne(ctx, bson.M{"_id": noteId, "userId": userId, "deletedAt": bson.M{"$ne": nil}}).Decode(&note)
+		if err != nil {
+			if err == mongo.ErrNoDocuments {
+				return errors.New("note not found")
+			}
+			return err
+		}
+
+		count, err := r.books.CountDocuments(ctx, bson.M{"_id": note.BookId, "userId": userId, "deletedAt": nil})
+		if err != nil {
+			return err
+		}
+		if count == 0 {
+			return errors.New("book is in the trash")
+		}
+
+		restored, err := r.restore(ctx, r.notes, userId, noteId)
+		if err != nil {
+			return err
+		}
+
+		if !restored {
+			return errors.New("note not found")
+		}
+
+		return nil
+	})
+}
+
+func (r *TrashRepositoryImpl) DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	filter := bson.M{"_id": bookId, "userId": userId, "deletedAt": bson.M{"$ne": nil}}
+
+	count, err := r.books.CountDocuments(context.Background(), filter)
+	if err != nil {
+		return err
+	}
+	if count == 0 {
+		return errors.New("book not found")
+	}
+
+	deleted, err := r.purgeBooks(filter, []primitive.ObjectID{bookId})
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return errors.New("book not found")
+	}
+
+	return nil
+}
+
+func (r *TrashRepositoryImpl) DeleteNote(userId primitive.ObjectID, noteId primitive.ObjectID) error {
+	return utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		deleted, err := r.notes.DeleteOne(ctx, bson.M{"_id": noteId, "userId": userId, "deletedAt": bson.M{"$ne": nil}})
+		if err != nil {
+			return err
+		}
+
+		if deleted.DeletedCount == 0 {
+			return errors.New("note not found")
+		}
+
+		if _, err := r.revisions.DeleteMany(ctx, bson.M{"noteId": noteId}); err != nil {
+			return err
+		}
+
+		_, err = r.note_labels.DeleteMany(ctx, bson.M{"noteId": noteId})
+		return err
+	})
+}
+
+// purgeBatchSize bounds how many books or notes Purge deletes per transaction.
+const purgeBatchSize = 500
+
+// Purge permanently deletes every book and note of any user that was moved to
+// the trash before the given time and returns how many were removed.
+func (r *TrashRepositoryImpl) Purge(before time.Time) (int64, error) {
+	filter := bson.M{"deletedAt": bson.M{"$lt": before}}
+
+	var purgedBooks int64
+	for {
+		bookIds, err := r.findIds(context.Background(), r.books, filter, options.Find().SetLimit(purgeBatchSize))
+		if err != nil {
+			return purgedBooks, err
+		}
+		if len(bookIds) == 0 {
+			break
+		}
+
+		deleted, err := r.purgeBooks(filter, bookIds)
+		purgedBooks += deleted
+		if err != nil {
+			return purgedBooks, err
+		}
+
+		if len(bookIds) < purgeBatchSize {
+			break
+		}
+	}
+
+	purgedNotes, err := r.purgeBatches(r.notes, filter, r.deleteNotes)
+
+	return purgedBooks + purgedNotes, err
+}
+
+// purgeBooks deletes the notes of the books purgeBatchSize at a time, each
+// chunk in its own transaction, and then the books matching filter together
+// with their labels. A few large books therefore never turn into a single
+// transaction that exceeds the server limits.
+func (r *TrashRepositoryImpl) purgeBooks(filter bson.M, bookIds []primitive.ObjectID) (int64, error) {
+	notes := bson.M{"bookId": bson.M{"$in": bookIds}}
+	if _, err := r.purgeBatches(r.notes, notes, r.deleteNotes); err != nil {
+		return 0, err
+	}
+
+	var deleted int64
+	err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		books := bson.M{"$and": bson.A{filter, bson.M{"_id": bson.M{"$in": bookIds}}}}
+		result, err := r.books.DeleteMany(ctx, books)
+		if err != nil {
+			return err
+		}
+		deleted = result.DeletedCount
+
+		_, err = r.book_labels.DeleteMany(ctx, bson.M{"bookId": bson.M{"$in": bookIds}})
+		return err
+	})
+
+	return deleted, err
+}
+
+// purgeBatches deletes the documents matching filter purgeBatchSize at a time,
+// each batch in its own transaction.
+func (r *TrashRepositoryImpl) purgeBatches(collection *mongo.Collection, filter bson.M, purge func(ctx mongo.SessionContext, ids []primitive.ObjectID) (int64, error)) (int64, error) {
+	var purged int64
+
+	for {
+		var found int
+		var deleted int64
+
+		err := utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+			ids, err := r.findIds(ctx, collection, filter, options.Find().SetLimit(purgeBatchSize))
+			if err != nil {
+				return err
+			}
+
+			found = len(ids)
+			deleted, err = purge(ctx, ids)
+			return err
+		})
+		if err != nil {
+			return purged, err
+		}
+
+		purged += deleted
+		if found < purgeBatchSize {
+			return purged, nil
+		}
+	}
+}
+
+// deleteNotes removes notes together with their revisions and labels.
+func (r *TrashRepositoryImpl) deleteNotes(ctx mongo.SessionContext, noteIds []primitive.ObjectID) (int64, error) {
+	if len(noteIds) == 0 {
+		return 0, nil
+	}
+
+	deleted, err := r.notes.DeleteMany(ctx, bson.M{"_id": bson.M{"$in": noteIds}})
+	if err != nil {
+		return 0, err
+	}
+
+	if _, err := r.revisions.DeleteMany(ctx, bson.M{"noteId": bson.M{"$in": noteIds}}); err != nil {
+		return 0, err
+	}
+
+	if _, err := r.note_labels.DeleteMany(ctx, bson.M{"noteId": bson.M{"$in": noteIds}}); err != nil {
+		return 0, err
+	}
+
+	return deleted.DeletedCount, nil
+}
+
+func (r *TrashRepositoryImpl) findIds(ctx context.Context, collection *mongo.Collection, filter bson.M, opts ...*options.FindOptions) ([]primitive.ObjectID, error) {
+	opts = append(opts, options.Find().SetProjection(bson.M{"_id": 1}))
+	cursor, err := collection.Find(ctx, filter, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	var documents []struct {
+		ID primitive.ObjectID `bson:"_id"`
+	}
+	if err := cursor.All(ctx, &documents); err != nil {
+		return nil, err
+	}
+
+	ids := make([]primitive.ObjectID, 0, len(documents))
+	for _, document := range documents {
+		ids = append(ids, document.ID)
+	}
+
+	return ids, nil
+}
@@ -0,0 +1,37 @@
+package trash_router
+
+import (
+	"log"
+	"noto/internal/config"
+	"noto/internal/middleware"
+	"noto/internal/services/trash/handler"
+	"noto/internal/services/trash/repository"
+	"noto/internal/services/trash/service"
+	"noto/internal/utils"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+const purgeInterval = time.Hour
+
+func TrashRouter(router fiber.Router) {
+	var repo = repository.NewTrashRepository(config.DB)
+	var serv = service.NewTrashService(repo, config.TrashRetention)
+	var hand = handler.NewTrashHandler(serv)
+
+	if err := repo.EnsureIndexes(); err != nil {
+		log.Println("Failed to create trash indexes:", err)
+	}
+	serv.StartPurger(purgeInterval)
+
+	var read = middleware.RequireScope(utils.ScopeBooksRead, utils.ScopeNotesRead)
+	var writeBooks = middleware.RequireScope(utils.ScopeBooksWrite)
+	var writeNotes = middleware.RequireScope(utils.ScopeNotesWrite)
+
+	router.Get("/trash", read, hand.GetTrash)
+	router.Post("/trash/books/:bookId/restore", writeBooks, hand.RestoreBook)
+	router.Delete("/trash/books/:bookId", writeBooks, hand.DeleteBook)
+	router.Post("/trash/notes/:noteId/restore", writeNotes, hand.RestoreNote)
+	router.Delete("/trash/notes/:noteId", writeNotes, hand.DeleteNote)
+}
@@ -0,0 +1,82 @@
+package service
+
+import (
+	"log"
+	"noto/internal/services/trash/model"
+	"noto/internal/services/trash/repository"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+type TrashService interface {
+	GetTrash(userId primitive.ObjectID, page int, limit int) (*model.PaginatedTrashResponse, error)
+	RestoreBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
+	RestoreNote(userId primitive.ObjectID, noteId primitive.ObjectID) error
+	DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error
+	DeleteNote(userId primitive.ObjectID, noteId primitive.ObjectID) error
+	Purge() (int64, error)
+	StartPurger(interval time.Duration)
+}
+
+type TrashServiceImpl struct {
+	trashRepo repository.TrashRepository
+	retention time.Duration
+}
+
+func NewTrashService(trashRepo repository.TrashRepository, retention time.Duration) TrashService {
+	return &TrashServiceImpl{trashRepo: trashRepo, retention: retention}
+}
+
+func (s *TrashServiceImpl) GetTrash(userId primitive.ObjectID, page int, limit int) (*model.PaginatedTrashResponse, error) {
+	return s.trashRepo.GetTrash(userId, page, limit)
+}
+
+func (s *TrashServiceImpl) RestoreBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	return s.trashRepo.RestoreBook(userId, bookId)
+}
+
+func (s *TrashServiceImpl) RestoreNote(userId primitive.ObjectID, noteId primitive.ObjectID) error {
+	return s.trashRepo.RestoreNote(userId, noteId)
+}
+
+func (s *TrashServiceImpl) DeleteBook(userId primitive.ObjectID, bookId primitive.ObjectID) error {
+	return s.trashRepo.DeleteBook(userId, bookId)
+}
+
+func (s *TrashServiceImpl) DeleteNote(userId primitive.ObjectID, noteId primitive.ObjectID) error {
+	return s.trashRepo.DeleteNote(userId, noteId)
+}
+
+// Purge removes everything that has been in the trash longer than the
+// retention. A retention of zero or less keeps trashed items forever.
+func (s *TrashServiceImpl) Purge() (int64, error) {
+	if s.retention <= 0 {
+		return 0, nil
+	}
+
+	return s.trashRepo.Purge(time.Now().Add(-s.retention))
+}
+
+// StartPurger runs Purge in the background every interval.
+func (s *TrashServiceImpl) StartPurger(interval time.Duration) {
+	if s.retention <= 0 {
+		return
+	}
+
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+
+		for ; true; <-ticker.C {
+			purged, err := s.Purge()
+			if err != nil {
+				log.Println("Failed to purge trash:", err)
+				continue
+			}
+			if purged > 0 {
+				log.Println("Purged items from trash:", purged)
+			}
+		}
+	}()
+}
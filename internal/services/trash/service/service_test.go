@@ -0,0 +1,41 @@
+package service
+
+import (
+	"noto/internal/services/trash/repository"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type stubTrashRepo struct {
+	repository.TrashRepository
+	before *time.Time
+}
+
+func (r *stubTrashRepo) Purge(before time.Time) (int64, error) {
+	r.before = &before
+	return 3, nil
+}
+
+func TestPurge(t *testing.T) {
+	repo := &stubTrashRepo{}
+	serv := NewTrashService(repo, 24*time.Hour)
+
+	purged, err := serv.Purge()
+	require.NoError(t, err)
+	assert.Equal(t, int64(3), purged)
+	require.NotNil(t, repo.before)
+	assert.WithinDuration(t, time.Now().Add(-24*time.Hour), *repo.before, time.Second)
+}
+
+func TestPurgeDisabled(t *testing.T) {
+	repo := &stubTrashRepo{}
+	serv := NewTrashService(repo, 0)
+
+	purged, err := serv.Purge()
+	require.NoError(t, err)
+	assert.Zero(t, purged)
+	assert.Nil(t, repo.before)
+}
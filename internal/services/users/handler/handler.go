@@ -0,0 +1,123 @@
+package handler
+
+import (
+	_ "noto/internal/common"
+	"noto/internal/services/users/model"
+	"noto/internal/services/users/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+type UserHandler interface {
+	GetUser(c *fiber.Ctx) error
+	UpdateUser(c *fiber.Ctx) error
+	DeleteUser(c *fiber.Ctx) error
+}
+
+type UserHandlerImpl struct {
+	userService service.UserService
+}
+
+func NewUserHandler(userService service.UserService) UserHandler {
+	return &UserHandlerImpl{userService: userService}
+}
+
+// GetUser
+// @Summary		Get current user
+// @Description	Get the profile of the authenticated user
+// @Tags		Users
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Success		200		{object}	model.UserResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/me [get]
+func (s *UserHandlerImpl) GetUser(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	user, err := s.userService.GetUser(userId)
+	if err != nil {
+		if err.Error() == "user not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(user)
+}
+
+// UpdateUser
+// @Summary		Update current user
+// @Description	Update the profile of the authenticated user. Only the fields present in the body are changed, preferences are replaced as a whole.
+// @Tags		Users
+// @Security 	BearerAuth
+// @Accept		json
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Param		user	body		model.UserUpdateSwagger	true	"Profile fields to update"
+// @Success		200		{object}	model.UserResponse
+// @Failure     400     {object}    common.ErrorResponse
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/me [patch]
+func (s *UserHandlerImpl) UpdateUser(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	user := new(model.UserUpdate)
+	if err := c.BodyParser(user); err != nil {
+		return utils.ErrorBadRequest(c, "failed to parse json: "+err.Error())
+	}
+
+	updatedUser, err := s.userService.UpdateUser(userId, user)
+	if err != nil {
+		switch err.Error() {
+		case "name is required", "invalid photo url", "invalid timezone", "invalid locale":
+			return utils.ErrorBadRequest(c, err.Error())
+		case "user not found":
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, err.Error())
+	}
+
+	return c.JSON(updatedUser)
+}
+
+// DeleteUser
+// @Summary		Delete current user
+// @Description	Permanently delete the authenticated user together with all books, notes, labels, sessions and tokens
+// @Tags		Users
+// @Security 	BearerAuth
+// @Produce		json
+// @Param 		Authorization header string false "Bearer token"
+// @Success		200		{object}	interface{}
+// @Failure     401     {object}    common.ErrorResponse
+// @Failure     404     {object}    common.ErrorResponse
+// @Failure     500     {object}    common.ErrorResponse
+// @Router		/api/me [delete]
+func (s *UserHandlerImpl) DeleteUser(c *fiber.Ctx) error {
+	userId, err := utils.GetUserID(c)
+	if err != nil {
+		return utils.ErrorUnauthorized(c, err.Error())
+	}
+
+	if err := s.userService.DeleteUser(userId); err != nil {
+		if err.Error() == "user not found" {
+			return utils.ErrorNotFound(c, err.Error())
+		}
+		return utils.ErrorInternalServer(c, "failed to delete account: "+err.Error())
+	}
+
+	return c.JSON(fiber.Map{
+		"success": "account deleted",
+	})
+}
@@ -0,0 +1,37 @@
+package model
+
+import (
+	"time"
+)
+
+type UserResponse struct {
+	ID          string                 `json:"id" bson:"_id"`
+	Email       string                 `json:"email" bson:"email"`
+	Name        string                 `json:"name" bson:"name"`
+	PhotoURL    string                 `json:"photo_url" bson:"photoUrl"`
+	Timezone    string                 `json:"timezone" bson:"timezone"`
+	Locale      string                 `json:"locale" bson:"locale"`
+	Preferences map[string]interface{} `json:"preferences" bson:"preferences"`
+	Password    string                 `json:"-" bson:"password,omitempty"`
+	HasPassword bool                   `json:"has_password" bson:"-"`
+	TOTPEnabled bool                   `json:"totp_enabled" bson:"totpEnabled"`
+	CreatedAt   time.Time              `json:"created_at" bson:"createdAt"`
+	UpdatedAt   time.Time              `json:"updated_at" bson:"updatedAt"`
+}
+
+type UserUpdateSwagger struct {
+	Name        string                 `json:"name" example:"Jane Doe"`
+	PhotoURL    string                 `json:"photo_url" example:"https://example.com/jane.png"`
+	Timezone    string                 `json:"timezone" example:"Asia/Jakarta"`
+	Locale      string                 `json:"locale" example:"id-ID"`
+	Preferences map[string]interface{} `json:"preferences"`
+}
+
+type UserUpdate struct {
+	Name        *string                 `json:"name" bson:"name,omitempty"`
+	PhotoURL    *string                 `json:"photo_url" bson:"photoUrl,omitempty"`
+	Timezone    *string                 `json:"timezone" bson:"timezone,omitempty"`
+	Locale      *string                 `json:"locale" bson:"locale,omitempty"`
+	Preferences *map[string]interface{} `json:"preferences" bson:"preferences,omitempty"`
+	UpdatedAt   time.Time               `json:"-" bson:"updatedAt"`
+}
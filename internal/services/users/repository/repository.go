@@ -0,0 +1,92 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"noto/internal/services/users/model"
+	"noto/internal/utils"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+)
+
+// userCollections holds every collection whose documents are owned through a
+// userId field and must be removed together with the account.
+var userCollections = []string{"books", "notes", "note_revisions", "labels", "book_labels", "note_labels", "sessions", "personal_tokens"}
+
+type UserRepository interface {
+	GetUser(userId primitive.ObjectID) (*model.UserResponse, error)
+	UpdateUser(userId primitive.ObjectID, user *model.UserUpdate) (*model.UserResponse, error)
+	DeleteUser(userId primitive.ObjectID) error
+}
+
+type UserRepositoryImpl struct {
+	db    *mongo.Database
+	users *mongo.Collection
+}
+
+func NewUserRepository(db *mongo.Database) UserRepository {
+	return &UserRepositoryImpl{db: db, users: db.Collection("users")}
+}
+
+func (r *UserRepositoryImpl) GetUser(userId primitive.ObjectID) (*model.UserResponse, error) {
+	var user model.UserResponse
+	err := r.users.FindOne(context.Background(), bson.M{"_id": userId}).Decode(&user)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return withDefaults(&user), nil
+}
+
+func (r *UserRepositoryImpl) UpdateUser(userId primitive.ObjectID, user *model.UserUpdate) (*model.UserResponse, error) {
+	user.UpdatedAt = time.Now()
+
+	var updated model.UserResponse
+	opts := options.FindOneAndUpdate().SetReturnDocument(options.After)
+	err := r.users.FindOneAndUpdate(context.Background(), bson.M{"_id": userId}, bson.M{"$set": user}, opts).Decode(&updated)
+	if err != nil {
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("user not found")
+		}
+		return nil, err
+	}
+
+	return withDefaults(&updated), nil
+}
+
+func (r *UserRepositoryImpl) DeleteUser(userId primitive.ObjectID) error {
+	return utils.WithTransaction(context.Background(), r.db, func(ctx mongo.SessionContext) error {
+		deleted, err := r.users.DeleteOne(ctx, bson.M{"_id": userId})
+		if err != nil {
+			return err
+		}
+
+		if deleted.DeletedCount == 0 {
+			return errors.New("user not found")
+		}
+
+		for _, name := range userCollections {
+			if _, err := r.db.Collection(name).DeleteMany(ctx, bson.M{"userId": userId}); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+func withDefaults(user *model.UserResponse) *model.UserResponse {
+	user.HasPassword = user.Password != ""
+	if user.Preferences == nil {
+		user.Preferences = map[string]interface{}{}
+	}
+
+	return user
+}
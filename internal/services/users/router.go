@@ -0,0 +1,26 @@
+package users_router
+
+import (
+	"noto/internal/config"
+	"noto/internal/middleware"
+	auth_repository "noto/internal/services/auth/repository"
+	"noto/internal/services/users/handler"
+	"noto/internal/services/users/repository"
+	"noto/internal/services/users/service"
+	"noto/internal/utils"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+func UsersRouter(router fiber.Router) {
+	var repo = repository.NewUserRepository(config.DB)
+	var serv = service.NewUserService(repo, auth_repository.NewAuthRepository(config.DB), config.AccessTokenTTL)
+	var hand = handler.NewUserHandler(serv)
+
+	var read = middleware.RequireScope(utils.ScopeAccountRead)
+	var write = middleware.RequireScope(utils.ScopeAccountWrite)
+
+	router.Get("/me", read, hand.GetUser)
+	router.Patch("/me", write, hand.UpdateUser)
+	router.Delete("/me", write, hand.DeleteUser)
+}
@@ -0,0 +1,79 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"noto/internal/services/auth/repository"
+	"noto/internal/services/users/model"
+	user_repository "noto/internal/services/users/repository"
+	"strings"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"golang.org/x/text/language"
+)
+
+type UserService interface {
+	GetUser(userId primitive.ObjectID) (*model.UserResponse, error)
+	UpdateUser(userId primitive.ObjectID, user *model.UserUpdate) (*model.UserResponse, error)
+	DeleteUser(userId primitive.ObjectID) error
+}
+
+type UserServiceImpl struct {
+	userRepo       user_repository.UserRepository
+	authRepo       repository.AuthRepository
+	accessTokenTTL time.Duration
+}
+
+func NewUserService(userRepo user_repository.UserRepository, authRepo repository.AuthRepository, accessTokenTTL time.Duration) UserService {
+	return &UserServiceImpl{userRepo: userRepo, authRepo: authRepo, accessTokenTTL: accessTokenTTL}
+}
+
+func (s *UserServiceImpl) GetUser(userId primitive.ObjectID) (*model.UserResponse, error) {
+	return s.userRepo.GetUser(userId)
+}
+
+func (s *UserServiceImpl) UpdateUser(userId primitive.ObjectID, user *model.UserUpdate) (*model.UserResponse, error) {
+	if user.Name != nil {
+		name := strings.TrimSpace(*user.Name)
+		if name == "" {
+			return nil, errors.New("name is required")
+		}
+		user.Name = &name
+	}
+
+	if user.PhotoURL != nil && *user.PhotoURL != "" {
+		photo, err := url.Parse(*user.PhotoURL)
+		if err != nil || (photo.Scheme != "http" && photo.Scheme != "https") || photo.Host == "" {
+			return nil, errors.New("invalid photo url")
+		}
+	}
+
+	if user.Timezone != nil && *user.Timezone != "" {
+		if _, err := time.LoadLocation(*user.Timezone); err != nil {
+			return nil, errors.New("invalid timezone")
+		}
+	}
+
+	if user.Locale != nil && *user.Locale != "" {
+		tag, err := language.Parse(*user.Locale)
+		if err != nil {
+			return nil, errors.New("invalid locale")
+		}
+		locale := tag.String()
+		user.Locale = &locale
+	}
+
+	return s.userRepo.UpdateUser(userId, user)
+}
+
+// DeleteUser revokes every session first so access tokens that are still
+// valid stop working, then removes the account and all of its data.
+func (s *UserServiceImpl) DeleteUser(userId primitive.ObjectID) error {
+	if err := s.authRepo.RevokeUserSessions(context.Background(), userId, primitive.NilObjectID, time.Now().Add(s.accessTokenTTL)); err != nil {
+		return err
+	}
+
+	return s.userRepo.DeleteUser(userId)
+}
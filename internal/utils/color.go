@@ -0,0 +1,10 @@
+package utils
+
+import "regexp"
+
+var hexColor = regexp.MustCompile(`^#[0-9a-fA-F]{6}$`)
+
+// IsHexColor reports whether color is written as #rrggbb.
+func IsHexColor(color string) bool {
+	return hexColor.MatchString(color)
+}
@@ -0,0 +1,28 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIsHexColor(t *testing.T) {
+	testCases := []struct {
+		name     string
+		color    string
+		expected bool
+	}{
+		{name: "Lowercase", color: "#ffcc00", expected: true},
+		{name: "Uppercase", color: "#A1B2C3", expected: true},
+		{name: "Short Form", color: "#fc0", expected: false},
+		{name: "Missing Hash", color: "ffcc00", expected: false},
+		{name: "Named Color", color: "red", expected: false},
+		{name: "Empty", color: "", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, IsHexColor(testCase.color))
+		})
+	}
+}
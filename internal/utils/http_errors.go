@@ -8,6 +8,11 @@ type ErrorResponse struct {
 	Error string `json:"error"`
 }
 
+type ConflictResponse struct {
+	Error   string      `json:"error"`
+	Current interface{} `json:"current"`
+}
+
 func sendErrorResponse(c *fiber.Ctx, status int, message string) error {
 	return c.Status(status).JSON(ErrorResponse{Error: message})
 }
@@ -36,6 +41,12 @@ func ErrorConflict(c *fiber.Ctx, message string) error {
 	return sendErrorResponse(c, fiber.StatusConflict, message)
 }
 
+// ErrorConflictWithCurrent reports a conflicting write together with the
+// current server copy so the client can merge and retry.
+func ErrorConflictWithCurrent(c *fiber.Ctx, message string, current interface{}) error {
+	return c.Status(fiber.StatusConflict).JSON(ConflictResponse{Error: message, Current: current})
+}
+
 func ErrorValidation(c *fiber.Ctx, message string) error {
 	return sendErrorResponse(c, fiber.StatusUnprocessableEntity, message)
 }
@@ -73,3 +73,16 @@ func TestSendErrorResponse(t *testing.T) {
 		})
 	}
 }
+
+func TestErrorConflictWithCurrent(t *testing.T) {
+	app := fiber.New()
+
+	ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+	defer app.ReleaseCtx(ctx)
+
+	err := ErrorConflictWithCurrent(ctx, "version conflict", fiber.Map{"id": "1", "version": 2})
+	require.NoError(t, err)
+
+	assert.Equal(t, fiber.StatusConflict, ctx.Response().StatusCode())
+	assert.JSONEq(t, `{"error":"version conflict","current":{"id":"1","version":2}}`, string(ctx.Response().Body()))
+}
@@ -0,0 +1,195 @@
+package utils
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// keysetCursor points at the last document of a page by its _id. Its sort key
+// values are looked up again on the server, so cursors stay short and never
+// carry document contents such as the text of a note. The sort itself is
+// stored too, so a cursor cannot be replayed against a listing sorted
+// differently.
+type keysetCursor struct {
+	Sort string             `bson:"s"`
+	ID   primitive.ObjectID `bson:"i"`
+}
+
+// KeysetLookup loads the document a cursor points at, with at least the
+// fields of the sort.
+type KeysetLookup func(id primitive.ObjectID) (bson.Raw, error)
+
+// KeysetDocument returns a KeysetLookup reading from collection, restricted
+// to the documents matching filter, such as those of the current user.
+func KeysetDocument(collection *mongo.Collection, filter bson.M) KeysetLookup {
+	return func(id primitive.ObjectID) (bson.Raw, error) {
+		query := bson.M{"_id": id}
+		for key, value := range filter {
+			query[key] = value
+		}
+
+		document, err := collection.FindOne(context.Background(), query).DecodeBytes()
+		if err == mongo.ErrNoDocuments {
+			return nil, errors.New("invalid cursor")
+		}
+
+		return document, err
+	}
+}
+
+// CursorQuery returns the cursor parameter and whether the request asked for
+// keyset pagination. An empty cursor requests the first page.
+func CursorQuery(c *fiber.Ctx) (string, bool) {
+	if !c.Context().QueryArgs().Has("cursor") {
+		return "", false
+	}
+
+	return c.Query("cursor"), true
+}
+
+// KeysetMatch returns the condition selecting the documents that come after
+// the cursor in the given sort, which must end with _id. lookup loads the
+// document the cursor points at. An empty cursor matches everything.
+func KeysetMatch(sort bson.D, cursor string, lookup KeysetLookup) (bson.M, error) {
+	if cursor == "" {
+		return bson.M{}, nil
+	}
+
+	raw, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	var decoded keysetCursor
+	if err := bson.Unmarshal(raw, &decoded); err != nil {
+		return nil, errors.New("invalid cursor")
+	}
+
+	if decoded.Sort != sortSignature(sort) || decoded.ID.IsZero() {
+		return nil, errors.New("invalid cursor")
+	}
+
+	document, err := lookup(decoded.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	values, err := keysetValues(sort, document)
+	if err != nil {
+		return nil, err
+	}
+
+	branches := bson.A{}
+	for i, key := range sort {
+		after, ok := keysetAfter(key.Key, sortOrder(key.Value), values[i])
+		if !ok {
+			continue
+		}
+
+		conditions := bson.A{}
+		for j := 0; j < i; j++ {
+			conditions = append(conditions, bson.M{sort[j].Key: values[j]})
+		}
+		conditions = append(conditions, after)
+
+		branches = append(branches, bson.M{"$and": conditions})
+	}
+
+	if len(branches) == 0 {
+		return bson.M{"_id": bson.M{"$exists": false}}, nil
+	}
+
+	return bson.M{"$or": branches}, nil
+}
+
+// keysetAfter returns the condition for key coming strictly after value.
+// Missing fields sort before every value, so they come first in ascending
+// order and last in descending order. ok is false when nothing can follow.
+func keysetAfter(key string, order int, value interface{}) (bson.M, bool) {
+	if order > 0 {
+		if value == nil {
+			return bson.M{key: bson.M{"$ne": nil}}, true
+		}
+		return bson.M{key: bson.M{"$gt": value}}, true
+	}
+
+	if value == nil {
+		return nil, false
+	}
+
+	return bson.M{"$or": bson.A{
+		bson.M{key: bson.M{"$lt": value}},
+		bson.M{key: nil},
+	}}, true
+}
+
+// KeysetPage trims documents fetched with limit+1 to a page of limit and
+// returns the cursor of the following page, or nil when this is the last one.
+func KeysetPage(sort bson.D, documents []bson.Raw, limit int) ([]bson.Raw, *string, error) {
+	if len(documents) <= limit {
+		return documents, nil, nil
+	}
+
+	id, ok := documents[limit-1].Lookup("_id").ObjectIDOK()
+	if !ok {
+		return nil, nil, errors.New("keyset documents need an object id")
+	}
+
+	raw, err := bson.Marshal(keysetCursor{Sort: sortSignature(sort), ID: id})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	next := base64.RawURLEncoding.EncodeToString(raw)
+	return documents[:limit], &next, nil
+}
+
+// keysetValues reads the sort key values of document, nil for missing fields.
+func keysetValues(sort bson.D, document bson.Raw) (bson.A, error) {
+	values := make(bson.A, 0, len(sort))
+	for _, key := range sort {
+		value, err := document.LookupErr(strings.Split(key.Key, ".")...)
+		if err != nil {
+			values = append(values, nil)
+			continue
+		}
+
+		var decoded interface{}
+		if err := value.Unmarshal(&decoded); err != nil {
+			return nil, err
+		}
+		values = append(values, decoded)
+	}
+
+	return values, nil
+}
+
+func sortSignature(sort bson.D) string {
+	keys := make([]string, 0, len(sort))
+	for _, key := range sort {
+		keys = append(keys, fmt.Sprintf("%s:%d", key.Key, sortOrder(key.Value)))
+	}
+
+	return strings.Join(keys, ",")
+}
+
+func sortOrder(value interface{}) int {
+	switch order := value.(type) {
+	case int:
+		return order
+	case int32:
+		return int(order)
+	case int64:
+		return int(order)
+	}
+
+	return 1
+}
@@ -0,0 +1,161 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func rawDocuments(t *testing.T, documents ...bson.D) []bson.Raw {
+	raws := make([]bson.Raw, 0, len(documents))
+	for _, document := range documents {
+		raw, err := bson.Marshal(document)
+		require.NoError(t, err)
+		raws = append(raws, raw)
+	}
+	return raws
+}
+
+// lookupIn finds cursor documents among documents, as KeysetDocument does in
+// a collection.
+func lookupIn(documents []bson.Raw) KeysetLookup {
+	return func(id primitive.ObjectID) (bson.Raw, error) {
+		for _, document := range documents {
+			if document.Lookup("_id").ObjectID() == id {
+				return document, nil
+			}
+		}
+		return nil, errors.New("invalid cursor")
+	}
+}
+
+func TestCursorQuery(t *testing.T) {
+	testCases := []struct {
+		name           string
+		query          string
+		expectedCursor string
+		expectedOk     bool
+	}{
+		{name: "No Cursor", query: "page=2", expectedCursor: "", expectedOk: false},
+		{name: "First Page", query: "cursor=", expectedCursor: "", expectedOk: true},
+		{name: "Next Page", query: "cursor=abc", expectedCursor: "abc", expectedOk: true},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+
+			ctx.Request().URI().SetQueryString(testCase.query)
+
+			cursor, ok := CursorQuery(ctx)
+			assert.Equal(t, testCase.expectedCursor, cursor)
+			assert.Equal(t, testCase.expectedOk, ok)
+		})
+	}
+}
+
+func TestKeysetPage(t *testing.T) {
+	sort := bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}
+	ids := []primitive.ObjectID{primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()}
+	documents := rawDocuments(t,
+		bson.D{{Key: "_id", Value: ids[0]}, {Key: "title", Value: "a"}},
+		bson.D{{Key: "_id", Value: ids[1]}, {Key: "title", Value: "b"}},
+		bson.D{{Key: "_id", Value: ids[2]}, {Key: "title", Value: "c"}},
+	)
+
+	page, next, err := KeysetPage(sort, documents, 3)
+	require.NoError(t, err)
+	assert.Len(t, page, 3)
+	assert.Nil(t, next, "the last page has no cursor")
+
+	page, next, err = KeysetPage(sort, documents, 2)
+	require.NoError(t, err)
+	assert.Len(t, page, 2)
+	require.NotNil(t, next)
+
+	match, err := KeysetMatch(sort, *next, lookupIn(documents))
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"$and": bson.A{bson.M{"title": bson.M{"$gt": "b"}}}},
+		bson.M{"$and": bson.A{bson.M{"title": "b"}, bson.M{"_id": bson.M{"$gt": ids[1]}}}},
+	}}, match)
+}
+
+func TestKeysetMatchMissingValues(t *testing.T) {
+	sort := bson.D{{Key: "pinned", Value: -1}, {Key: "position", Value: 1}, {Key: "_id", Value: -1}}
+	id := primitive.NewObjectID()
+
+	documents := rawDocuments(t,
+		bson.D{{Key: "_id", Value: id}, {Key: "pinned", Value: false}},
+		bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "pinned", Value: false}},
+	)
+	_, next, err := KeysetPage(sort, documents, 1)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+
+	match, err := KeysetMatch(sort, *next, lookupIn(documents))
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{"$or": bson.A{
+		bson.M{"$and": bson.A{bson.M{"$or": bson.A{
+			bson.M{"pinned": bson.M{"$lt": false}},
+			bson.M{"pinned": nil},
+		}}}},
+		bson.M{"$and": bson.A{bson.M{"pinned": false}, bson.M{"position": bson.M{"$ne": nil}}}},
+		bson.M{"$and": bson.A{bson.M{"pinned": false}, bson.M{"position": nil}, bson.M{"$or": bson.A{
+			bson.M{"_id": bson.M{"$lt": id}},
+			bson.M{"_id": nil},
+		}}}},
+	}}, match)
+}
+
+func TestKeysetMatchInvalid(t *testing.T) {
+	sort := bson.D{{Key: "title", Value: 1}, {Key: "_id", Value: 1}}
+
+	documents := rawDocuments(t,
+		bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "title", Value: "a"}},
+		bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "title", Value: "b"}},
+	)
+
+	match, err := KeysetMatch(sort, "", lookupIn(documents))
+	require.NoError(t, err)
+	assert.Equal(t, bson.M{}, match)
+
+	_, err = KeysetMatch(sort, "not a cursor!", lookupIn(documents))
+	assert.EqualError(t, err, "invalid cursor")
+
+	_, next, err := KeysetPage(sort, documents, 1)
+	require.NoError(t, err)
+
+	_, err = KeysetMatch(bson.D{{Key: "title", Value: -1}, {Key: "_id", Value: -1}}, *next, lookupIn(documents))
+	assert.EqualError(t, err, "invalid cursor", "a cursor only works with the sort it was made for")
+
+	_, err = KeysetMatch(sort, *next, lookupIn(nil))
+	assert.EqualError(t, err, "invalid cursor", "the cursor document must still exist")
+}
+
+func TestKeysetPageOmitsValues(t *testing.T) {
+	sort := bson.D{{Key: "text", Value: 1}, {Key: "_id", Value: 1}}
+	text := strings.Repeat("secret note content ", 500)
+	documents := rawDocuments(t,
+		bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "text", Value: text}},
+		bson.D{{Key: "_id", Value: primitive.NewObjectID()}, {Key: "text", Value: text + "!"}},
+	)
+
+	_, next, err := KeysetPage(sort, documents, 1)
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Less(t, len(*next), 100, "the cursor holds the _id only")
+
+	match, err := KeysetMatch(sort, *next, lookupIn(documents))
+	require.NoError(t, err)
+	assert.Contains(t, match["$or"].(bson.A)[0].(bson.M)["$and"], bson.M{"text": bson.M{"$gt": text}})
+}
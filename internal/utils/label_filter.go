@@ -0,0 +1,137 @@
+package utils
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const maxFilterLabels = 20
+
+// LabelFilter selects documents by label names: labels=a,b with match=all
+// (every label, the default) or match=any (at least one), and exclude=c,d
+// for labels that must not be present.
+type LabelFilter struct {
+	Labels   []string
+	MatchAll bool
+	Exclude  []string
+}
+
+// ParseLabelFilter reads the labels, match and exclude parameters of the
+// request.
+func ParseLabelFilter(c *fiber.Ctx) (*LabelFilter, error) {
+	filter := &LabelFilter{MatchAll: true}
+
+	switch c.Query("match") {
+	case "", "all":
+	case "any":
+		filter.MatchAll = false
+	default:
+		return nil, errors.New("invalid match: must be all or any")
+	}
+
+	filter.Labels = splitLabels(c.Query("labels"))
+	filter.Exclude = splitLabels(c.Query("exclude"))
+
+	if len(filter.Labels) > maxFilterLabels || len(filter.Exclude) > maxFilterLabels {
+		return nil, fmt.Errorf("at most %d labels can be filtered on", maxFilterLabels)
+	}
+
+	for _, label := range filter.Exclude {
+		for _, included := range filter.Labels {
+			if label == included {
+				return nil, errors.New("label " + label + " is both included and excluded")
+			}
+		}
+	}
+
+	return filter, nil
+}
+
+// splitLabels splits a comma separated list, dropping blanks and repeats.
+func splitLabels(value string) []string {
+	labels := []string{}
+	seen := map[string]bool{}
+
+	for _, label := range strings.Split(value, ",") {
+		label = strings.TrimSpace(label)
+		if label == "" || seen[label] {
+			continue
+		}
+		seen[label] = true
+		labels = append(labels, label)
+	}
+
+	return labels
+}
+
+// Names returns every label name the filter refers to.
+func (f *LabelFilter) Names() []string {
+	return append(append([]string{}, f.Labels...), f.Exclude...)
+}
+
+// Match returns the condition of the filter on the _id of documents, given
+// the ids of the documents tagged with each label name of the filter. It is
+// empty when nothing is filtered.
+func (f *LabelFilter) Match(tagged map[string][]primitive.ObjectID) bson.D {
+	condition := bson.M{}
+
+	if len(f.Labels) > 0 {
+		if f.MatchAll {
+			condition["$in"] = intersectIds(tagged, f.Labels)
+		} else {
+			condition["$in"] = unionIds(tagged, f.Labels)
+		}
+	}
+
+	if len(f.Exclude) > 0 {
+		condition["$nin"] = unionIds(tagged, f.Exclude)
+	}
+
+	if len(condition) == 0 {
+		return bson.D{}
+	}
+
+	return bson.D{{Key: "_id", Value: condition}}
+}
+
+// unionIds returns the ids tagged with any of the names, in order of first
+// appearance.
+func unionIds(tagged map[string][]primitive.ObjectID, names []string) []primitive.ObjectID {
+	ids := []primitive.ObjectID{}
+	seen := map[primitive.ObjectID]bool{}
+
+	for _, name := range names {
+		for _, id := range tagged[name] {
+			if !seen[id] {
+				seen[id] = true
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// intersectIds returns the ids tagged with every one of the names.
+func intersectIds(tagged map[string][]primitive.ObjectID, names []string) []primitive.ObjectID {
+	counts := map[primitive.ObjectID]int{}
+	for _, name := range names {
+		for _, id := range unionIds(tagged, []string{name}) {
+			counts[id]++
+		}
+	}
+
+	ids := []primitive.ObjectID{}
+	for _, id := range unionIds(tagged, names[:1]) {
+		if counts[id] == len(names) {
+			ids = append(ids, id)
+		}
+	}
+
+	return ids
+}
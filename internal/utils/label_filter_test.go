@@ -0,0 +1,94 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+func TestParseLabelFilter(t *testing.T) {
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *LabelFilter
+		expectedError string
+	}{
+		{
+			name:     "No Parameters",
+			query:    "",
+			expected: &LabelFilter{Labels: []string{}, MatchAll: true, Exclude: []string{}},
+		},
+		{
+			name:     "Match All By Default",
+			query:    "labels=work,%20urgent%20,,work&exclude=done",
+			expected: &LabelFilter{Labels: []string{"work", "urgent"}, MatchAll: true, Exclude: []string{"done"}},
+		},
+		{
+			name:     "Match Any",
+			query:    "labels=work,home&match=any",
+			expected: &LabelFilter{Labels: []string{"work", "home"}, Exclude: []string{}},
+		},
+		{
+			name:          "Unknown Match",
+			query:         "labels=work&match=some",
+			expectedError: "invalid match: must be all or any",
+		},
+		{
+			name:          "Included And Excluded",
+			query:         "labels=work&exclude=work",
+			expectedError: "label work is both included and excluded",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+
+			ctx.Request().URI().SetQueryString(testCase.query)
+
+			filter, err := ParseLabelFilter(ctx)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, filter)
+		})
+	}
+}
+
+func TestLabelFilterMatch(t *testing.T) {
+	first, second, third := primitive.NewObjectID(), primitive.NewObjectID(), primitive.NewObjectID()
+	tagged := map[string][]primitive.ObjectID{
+		"work":   {first, second},
+		"urgent": {first, first},
+		"home":   {third},
+		"done":   {second},
+	}
+
+	all := &LabelFilter{Labels: []string{"work", "urgent"}, MatchAll: true, Exclude: []string{"done"}}
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: bson.M{"$in": []primitive.ObjectID{first}, "$nin": []primitive.ObjectID{second}}},
+	}, all.Match(tagged))
+
+	anyOf := &LabelFilter{Labels: []string{"work", "home"}}
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: bson.M{"$in": []primitive.ObjectID{first, second, third}}},
+	}, anyOf.Match(tagged))
+
+	unknown := &LabelFilter{Labels: []string{"work", "missing"}, MatchAll: true}
+	assert.Equal(t, bson.D{
+		{Key: "_id", Value: bson.M{"$in": []primitive.ObjectID{}}},
+	}, unknown.Match(tagged))
+
+	assert.Equal(t, bson.D{}, (&LabelFilter{MatchAll: true}).Match(tagged))
+	assert.Equal(t, []string{"work", "urgent", "done"}, all.Names())
+}
@@ -0,0 +1,31 @@
+package utils
+
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
+
+// NoteLabelStages embeds the labels of each note. The lookups run once per
+// note, so they belong after the notes have been sorted and paginated.
+func NoteLabelStages() mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "note_labels",
+			"localField":   "_id",
+			"foreignField": "noteId",
+			"as":           "note_labels",
+		}}},
+		{{Key: "$lookup", Value: bson.M{
+			"from":         "labels",
+			"localField":   "note_labels.labelId",
+			"foreignField": "_id",
+			"as":           "labels",
+		}}},
+		{{Key: "$project", Value: bson.M{
+			"note_labels":      0,
+			"labels.userId":    0,
+			"labels.createdAt": 0,
+			"labels.updatedAt": 0,
+		}}},
+	}
+}
@@ -0,0 +1,143 @@
+package utils
+
+import (
+	"errors"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+const maxPrefixLength = 200
+
+// ListQuery holds the sorting and filtering parameters shared by list
+// endpoints: sort=<field>[:asc|:desc], created_after, created_before,
+// updated_after, updated_before and prefix.
+type ListQuery struct {
+	SortField     string
+	SortOrder     int
+	CreatedAfter  *time.Time
+	CreatedBefore *time.Time
+	UpdatedAfter  *time.Time
+	UpdatedBefore *time.Time
+	Prefix        string
+}
+
+// ParseListQuery reads the list parameters of the request. sortFields maps the
+// names accepted in the sort parameter to document fields.
+func ParseListQuery(c *fiber.Ctx, sortFields map[string]string) (*ListQuery, error) {
+	query := &ListQuery{SortOrder: 1}
+
+	if value := c.Query("sort"); value != "" {
+		name, order, _ := strings.Cut(value, ":")
+
+		field, ok := sortFields[name]
+		if !ok {
+			names := make([]string, 0, len(sortFields))
+			for name := range sortFields {
+				names = append(names, name)
+			}
+			sort.Strings(names)
+			return nil, errors.New("invalid sort: must be one of " + strings.Join(names, ", "))
+		}
+		query.SortField = field
+
+		switch order {
+		case "", "asc":
+		case "desc":
+			query.SortOrder = -1
+		default:
+			return nil, errors.New("invalid sort order: must be asc or desc")
+		}
+	}
+
+	var err error
+	for _, param := range []struct {
+		name   string
+		target **time.Time
+	}{
+		{"created_after", &query.CreatedAfter},
+		{"created_before", &query.CreatedBefore},
+		{"updated_after", &query.UpdatedAfter},
+		{"updated_before", &query.UpdatedBefore},
+	} {
+		if *param.target, err = parseQueryTime(c.Query(param.name)); err != nil {
+			return nil, errors.New("invalid " + param.name + ": use RFC 3339 or YYYY-MM-DD")
+		}
+	}
+
+	if query.CreatedAfter != nil && query.CreatedBefore != nil && !query.CreatedAfter.Before(*query.CreatedBefore) {
+		return nil, errors.New("created_after must be before created_before")
+	}
+
+	if query.UpdatedAfter != nil && query.UpdatedBefore != nil && !query.UpdatedAfter.Before(*query.UpdatedBefore) {
+		return nil, errors.New("updated_after must be before updated_before")
+	}
+
+	query.Prefix = strings.TrimSpace(c.Query("prefix"))
+	if len(query.Prefix) > maxPrefixLength {
+		return nil, errors.New("prefix is too long")
+	}
+
+	return query, nil
+}
+
+func parseQueryTime(value string) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+
+	for _, layout := range []string{time.RFC3339, time.DateOnly} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed, nil
+		}
+	}
+
+	return nil, errors.New("invalid time")
+}
+
+// Match returns the conditions of the query, matching the prefix
+// case-insensitively against prefixField.
+func (q *ListQuery) Match(prefixField string) bson.D {
+	match := bson.D{}
+
+	for _, condition := range []struct {
+		field    string
+		operator string
+		value    *time.Time
+	}{
+		{"createdAt", "$gt", q.CreatedAfter},
+		{"createdAt", "$lt", q.CreatedBefore},
+		{"updatedAt", "$gt", q.UpdatedAfter},
+		{"updatedAt", "$lt", q.UpdatedBefore},
+	} {
+		if condition.value != nil {
+			match = append(match, bson.E{Key: condition.field, Value: bson.M{condition.operator: *condition.value}})
+		}
+	}
+
+	if q.Prefix != "" {
+		match = append(match, bson.E{Key: prefixField, Value: bson.M{
+			"$regex":   "^" + regexp.QuoteMeta(q.Prefix),
+			"$options": "i",
+		}})
+	}
+
+	return match
+}
+
+// Sort returns the requested order with _id as tie breaker, or defaultSort
+// when the request did not ask for one.
+func (q *ListQuery) Sort(defaultSort bson.D) bson.D {
+	if q.SortField == "" {
+		return defaultSort
+	}
+
+	return bson.D{
+		{Key: q.SortField, Value: q.SortOrder},
+		{Key: "_id", Value: q.SortOrder},
+	}
+}
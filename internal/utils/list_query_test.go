@@ -0,0 +1,112 @@
+package utils
+
+import (
+	"testing"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+var testSortFields = map[string]string{
+	"created_at": "createdAt",
+	"title":      "title",
+}
+
+func TestParseListQuery(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	later := time.Date(2024, 5, 2, 10, 30, 0, 0, time.UTC)
+
+	testCases := []struct {
+		name          string
+		query         string
+		expected      *ListQuery
+		expectedError string
+	}{
+		{
+			name:     "No Parameters",
+			query:    "",
+			expected: &ListQuery{SortOrder: 1},
+		},
+		{
+			name:     "Sort Ascending By Default",
+			query:    "sort=title",
+			expected: &ListQuery{SortField: "title", SortOrder: 1},
+		},
+		{
+			name:     "Sort Descending",
+			query:    "sort=created_at:desc",
+			expected: &ListQuery{SortField: "createdAt", SortOrder: -1},
+		},
+		{
+			name:     "Date Filters",
+			query:    "created_after=2024-05-01&updated_before=2024-05-02T10:30:00Z&prefix=%20Meet%20",
+			expected: &ListQuery{SortOrder: 1, CreatedAfter: &day, UpdatedBefore: &later, Prefix: "Meet"},
+		},
+		{
+			name:          "Unknown Sort Field",
+			query:         "sort=color",
+			expectedError: "invalid sort: must be one of created_at, title",
+		},
+		{
+			name:          "Unknown Sort Order",
+			query:         "sort=title:up",
+			expectedError: "invalid sort order: must be asc or desc",
+		},
+		{
+			name:          "Invalid Date",
+			query:         "updated_after=yesterday",
+			expectedError: "invalid updated_after: use RFC 3339 or YYYY-MM-DD",
+		},
+		{
+			name:          "Empty Range",
+			query:         "created_after=2024-05-02&created_before=2024-05-01",
+			expectedError: "created_after must be before created_before",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+
+			ctx.Request().URI().SetQueryString(testCase.query)
+
+			query, err := ParseListQuery(ctx, testSortFields)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, query)
+		})
+	}
+}
+
+func TestListQueryMatch(t *testing.T) {
+	day := time.Date(2024, 5, 1, 0, 0, 0, 0, time.UTC)
+	query := &ListQuery{CreatedAfter: &day, UpdatedBefore: &day, Prefix: "a.b"}
+
+	assert.Equal(t, bson.D{
+		{Key: "createdAt", Value: bson.M{"$gt": day}},
+		{Key: "updatedAt", Value: bson.M{"$lt": day}},
+		{Key: "title", Value: bson.M{"$regex": `^a\.b`, "$options": "i"}},
+	}, query.Match("title"))
+
+	assert.Equal(t, bson.D{}, (&ListQuery{}).Match("title"))
+}
+
+func TestListQuerySort(t *testing.T) {
+	defaultSort := bson.D{{Key: "updatedAt", Value: -1}}
+
+	assert.Equal(t, defaultSort, (&ListQuery{SortOrder: 1}).Sort(defaultSort))
+	assert.Equal(t, bson.D{
+		{Key: "title", Value: -1},
+		{Key: "_id", Value: -1},
+	}, (&ListQuery{SortField: "title", SortOrder: -1}).Sort(defaultSort))
+}
@@ -1,6 +1,9 @@
 package utils
 
-import "go.mongodb.org/mongo-driver/bson"
+import (
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+)
 
 func PaginationAggregate(page int, limit int) bson.M {
 	skip := limit * (page - 1)
@@ -52,3 +55,21 @@ func PaginationAggregate(page int, limit int) bson.M {
 		},
 	}
 }
+
+// PaginationAggregateWith is PaginationAggregate with stages that only run on
+// the documents of the requested page, such as lookups that would be wasted
+// on every other document.
+func PaginationAggregateWith(page int, limit int, stages mongo.Pipeline) bson.M {
+	facet := PaginationAggregate(page, limit)
+
+	data := bson.A{}
+	for _, stage := range facet["data"].([]bson.M) {
+		data = append(data, stage)
+	}
+	for _, stage := range stages {
+		data = append(data, stage)
+	}
+	facet["data"] = data
+
+	return facet
+}
@@ -5,6 +5,7 @@ import (
 
 	"github.com/stretchr/testify/assert"
 	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
 )
 
 func TestPaginationAggregate(t *testing.T) {
@@ -75,3 +76,16 @@ func TestPaginationAggregate(t *testing.T) {
 		})
 	}
 }
+
+func TestPaginationAggregateWith(t *testing.T) {
+	lookup := bson.D{{Key: "$lookup", Value: bson.M{"from": "labels"}}}
+
+	facet := PaginationAggregateWith(3, 10, mongo.Pipeline{lookup})
+
+	assert.Equal(t, PaginationAggregate(3, 10)["metadata"], facet["metadata"])
+	assert.Equal(t, bson.A{
+		bson.M{"$skip": 20},
+		bson.M{"$limit": 10},
+		lookup,
+	}, facet["data"])
+}
@@ -0,0 +1,59 @@
+package utils
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidatePassword(t *testing.T) {
+	testCases := []struct {
+		name          string
+		password      string
+		expectedError string
+	}{
+		{
+			name:          "Valid Password",
+			password:      "secret-password",
+			expectedError: "",
+		},
+		{
+			name:          "Too Short",
+			password:      "short",
+			expectedError: "password must be at least 8 characters",
+		},
+		{
+			name:          "Too Long",
+			password:      strings.Repeat("a", 73),
+			expectedError: "password must be at most 72 characters",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidatePassword(testCase.password)
+
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHashPassword(t *testing.T) {
+	hash, err := HashPassword("secret-password")
+	require.NoError(t, err)
+
+	other, err := HashPassword("secret-password")
+	require.NoError(t, err)
+
+	assert.NotEqual(t, "secret-password", hash, "Hash should not be the plain password")
+	assert.NotEqual(t, hash, other, "Hash should be salted")
+	assert.True(t, CheckPassword(hash, "secret-password"))
+	assert.False(t, CheckPassword(hash, "wrong-password"))
+	assert.False(t, CheckPassword("", "secret-password"))
+}
@@ -0,0 +1,109 @@
+package utils
+
+import (
+	"errors"
+	"strings"
+
+	"go.mongodb.org/mongo-driver/bson/primitive"
+)
+
+const (
+	ScopeBooksRead    = "books:read"
+	ScopeBooksWrite   = "books:write"
+	ScopeNotesRead    = "notes:read"
+	ScopeNotesWrite   = "notes:write"
+	ScopeLabelsRead   = "labels:read"
+	ScopeLabelsWrite  = "labels:write"
+	ScopeTokensRead   = "tokens:read"
+	ScopeTokensWrite  = "tokens:write"
+	ScopeAccountRead  = "account:read"
+	ScopeAccountWrite = "account:write"
+	ScopeBookPrefix   = "book:"
+)
+
+var validScopes = map[string]bool{
+	ScopeBooksRead:    true,
+	ScopeBooksWrite:   true,
+	ScopeNotesRead:    true,
+	ScopeNotesWrite:   true,
+	ScopeLabelsRead:   true,
+	ScopeLabelsWrite:  true,
+	ScopeTokensRead:   true,
+	ScopeTokensWrite:  true,
+	ScopeAccountRead:  true,
+	ScopeAccountWrite: true,
+}
+
+func ValidateScopes(scopes []string) error {
+	for _, scope := range scopes {
+		if strings.HasPrefix(scope, ScopeBookPrefix) {
+			if _, err := primitive.ObjectIDFromHex(strings.TrimPrefix(scope, ScopeBookPrefix)); err != nil {
+				return errors.New("invalid scope: " + scope)
+			}
+			continue
+		}
+
+		if !validScopes[scope] {
+			return errors.New("invalid scope: " + scope)
+		}
+	}
+
+	return nil
+}
+
+// HasScope reports whether the granted scopes allow the required one. A write
+// scope also grants read access on the same resource.
+func HasScope(granted []string, required string) bool {
+	write := strings.TrimSuffix(required, ":read") + ":write"
+	for _, scope := range granted {
+		if scope == required || scope == write {
+			return true
+		}
+	}
+
+	return false
+}
+
+// HasBookAccess reports whether the granted scopes allow access to the book.
+// Tokens without any book:<id> scope are not restricted to specific books.
+func HasBookAccess(granted []string, bookId string) bool {
+	restricted := false
+	for _, scope := range granted {
+		if strings.HasPrefix(scope, ScopeBookPrefix) {
+			restricted = true
+			if bookId != "" && scope == ScopeBookPrefix+bookId {
+				return true
+			}
+		}
+	}
+
+	return !restricted
+}
+
+// ScopesSubset reports whether every requested scope is covered by the granted
+// scopes, so a restricted token can never create a broader one.
+func ScopesSubset(granted []string, requested []string) bool {
+	if len(requested) == 0 {
+		return false
+	}
+
+	for _, scope := range requested {
+		if strings.HasPrefix(scope, ScopeBookPrefix) {
+			if !HasBookAccess(granted, strings.TrimPrefix(scope, ScopeBookPrefix)) {
+				return false
+			}
+			continue
+		}
+
+		if !HasScope(granted, scope) {
+			return false
+		}
+	}
+
+	restricted := !HasBookAccess(granted, "")
+	if restricted && HasBookAccess(requested, "") {
+		return false
+	}
+
+	return true
+}
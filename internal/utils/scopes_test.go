@@ -0,0 +1,116 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidateScopes(t *testing.T) {
+	testCases := []struct {
+		name          string
+		scopes        []string
+		expectedError string
+	}{
+		{
+			name:          "Valid Scopes",
+			scopes:        []string{"books:read", "notes:write", "book:507f1f77bcf86cd799439011"},
+			expectedError: "",
+		},
+		{
+			name:          "Empty Scopes",
+			scopes:        []string{},
+			expectedError: "",
+		},
+		{
+			name:          "Unknown Scope",
+			scopes:        []string{"books:delete"},
+			expectedError: "invalid scope: books:delete",
+		},
+		{
+			name:          "Invalid Book Scope",
+			scopes:        []string{"book:123"},
+			expectedError: "invalid scope: book:123",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			err := ValidateScopes(testCase.scopes)
+
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+			} else {
+				assert.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestHasScope(t *testing.T) {
+	testCases := []struct {
+		name     string
+		granted  []string
+		required string
+		expected bool
+	}{
+		{name: "Exact Scope", granted: []string{"books:read"}, required: "books:read", expected: true},
+		{name: "Write Implies Read", granted: []string{"notes:write"}, required: "notes:read", expected: true},
+		{name: "Read Does Not Imply Write", granted: []string{"notes:read"}, required: "notes:write", expected: false},
+		{name: "Other Resource", granted: []string{"books:write"}, required: "notes:read", expected: false},
+		{name: "No Scopes", granted: []string{}, required: "books:read", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, HasScope(testCase.granted, testCase.required))
+		})
+	}
+}
+
+func TestHasBookAccess(t *testing.T) {
+	bookId := "507f1f77bcf86cd799439011"
+
+	testCases := []struct {
+		name     string
+		granted  []string
+		bookId   string
+		expected bool
+	}{
+		{name: "Unrestricted", granted: []string{"notes:write"}, bookId: bookId, expected: true},
+		{name: "Allowed Book", granted: []string{"notes:write", "book:" + bookId}, bookId: bookId, expected: true},
+		{name: "Other Book", granted: []string{"notes:write", "book:507f191e810c19729de860ea"}, bookId: bookId, expected: false},
+		{name: "Restricted Without Book", granted: []string{"book:" + bookId}, bookId: "", expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, HasBookAccess(testCase.granted, testCase.bookId))
+		})
+	}
+}
+
+func TestScopesSubset(t *testing.T) {
+	bookId := "507f1f77bcf86cd799439011"
+
+	testCases := []struct {
+		name      string
+		granted   []string
+		requested []string
+		expected  bool
+	}{
+		{name: "Same Scopes", granted: []string{"books:read"}, requested: []string{"books:read"}, expected: true},
+		{name: "Narrower Scopes", granted: []string{"notes:write"}, requested: []string{"notes:read"}, expected: true},
+		{name: "Broader Scopes", granted: []string{"notes:read"}, requested: []string{"notes:write"}, expected: false},
+		{name: "Full Access Requested", granted: []string{"notes:read"}, requested: []string{}, expected: false},
+		{name: "Keeps Book Restriction", granted: []string{"notes:write", "book:" + bookId}, requested: []string{"notes:write", "book:" + bookId}, expected: true},
+		{name: "Drops Book Restriction", granted: []string{"notes:write", "book:" + bookId}, requested: []string{"notes:write"}, expected: false},
+		{name: "Other Book", granted: []string{"notes:write", "book:" + bookId}, requested: []string{"notes:write", "book:507f191e810c19729de860ea"}, expected: false},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, ScopesSubset(testCase.granted, testCase.requested))
+		})
+	}
+}
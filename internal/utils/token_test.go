@@ -0,0 +1,44 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestGenerateRandomToken(t *testing.T) {
+	token, err := GenerateRandomToken(32)
+	require.NoError(t, err)
+
+	other, err := GenerateRandomToken(32)
+	require.NoError(t, err)
+
+	assert.Len(t, token, 43, "32 bytes should encode to 43 base64url characters")
+	assert.NotEqual(t, token, other, "Tokens should be random")
+}
+
+func TestHashToken(t *testing.T) {
+	testCases := []struct {
+		name     string
+		token    string
+		expected string
+	}{
+		{
+			name:     "Empty Token",
+			token:    "",
+			expected: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855",
+		},
+		{
+			name:     "Simple Token",
+			token:    "noto",
+			expected: "7e5ff07ea6fc5f6696de9d7368111a7b26d203bf54c5c827fdd6b97b8968c0be",
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, HashToken(testCase.token))
+		})
+	}
+}
@@ -20,3 +20,14 @@ func GetUserID(c *fiber.Ctx) (primitive.ObjectID, error) {
 
 	return objUserId, nil
 }
+
+// CanAccessBook reports whether the credential of the request may touch the
+// book. Browser sessions carry no scopes and can access every book.
+func CanAccessBook(c *fiber.Ctx, bookId string) bool {
+	granted, ok := c.Locals("scopes").([]string)
+	if !ok {
+		return true
+	}
+
+	return HasBookAccess(granted, bookId)
+}
@@ -64,3 +64,51 @@ func TestGetUserID(t *testing.T) {
 		})
 	}
 }
+
+func TestCanAccessBook(t *testing.T) {
+	bookId := "507f1f77bcf86cd799439011"
+
+	testCases := []struct {
+		name         string
+		setupContext func(*fiber.Ctx)
+		expected     bool
+	}{
+		{
+			name:         "No Scopes",
+			setupContext: func(c *fiber.Ctx) {},
+			expected:     true,
+		},
+		{
+			name: "Unrestricted Token",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("scopes", []string{ScopeNotesWrite})
+			},
+			expected: true,
+		},
+		{
+			name: "Allowed Book",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("scopes", []string{ScopeNotesWrite, ScopeBookPrefix + bookId})
+			},
+			expected: true,
+		},
+		{
+			name: "Other Book",
+			setupContext: func(c *fiber.Ctx) {
+				c.Locals("scopes", []string{ScopeNotesWrite, ScopeBookPrefix + "507f1f77bcf86cd799439012"})
+			},
+			expected: false,
+		},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+
+			testCase.setupContext(ctx)
+			assert.Equal(t, testCase.expected, CanAccessBook(ctx, bookId))
+		})
+	}
+}
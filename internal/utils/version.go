@@ -0,0 +1,52 @@
+package utils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+
+	"github.com/gofiber/fiber/v2"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+// ETag formats a document version as a strong entity tag.
+func ETag(version int64) string {
+	return `"` + strconv.FormatInt(version, 10) + `"`
+}
+
+func SetETag(c *fiber.Ctx, version int64) {
+	c.Set(fiber.HeaderETag, ETag(version))
+}
+
+// IfMatchVersion returns the version the client expects from the If-Match
+// header, or nil when the header is missing or "*" and any version is fine.
+func IfMatchVersion(c *fiber.Ctx) (*int64, error) {
+	header := strings.TrimSpace(c.Get(fiber.HeaderIfMatch))
+	if header == "" || header == "*" {
+		return nil, nil
+	}
+
+	tag := strings.Trim(strings.TrimPrefix(header, "W/"), `"`)
+	version, err := strconv.ParseInt(tag, 10, 64)
+	if err != nil || version < 0 {
+		return nil, errors.New("invalid If-Match header")
+	}
+
+	return &version, nil
+}
+
+// MatchVersion restricts filter to documents at the expected version.
+// Documents created before versioning have no version field and count as 0.
+func MatchVersion(filter bson.M, version *int64) bson.M {
+	if version == nil {
+		return filter
+	}
+
+	if *version == 0 {
+		filter["version"] = bson.M{"$in": bson.A{0, nil}}
+	} else {
+		filter["version"] = *version
+	}
+
+	return filter
+}
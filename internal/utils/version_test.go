@@ -0,0 +1,76 @@
+package utils
+
+import (
+	"testing"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/valyala/fasthttp"
+	"go.mongodb.org/mongo-driver/bson"
+)
+
+func TestETag(t *testing.T) {
+	assert.Equal(t, `"0"`, ETag(0))
+	assert.Equal(t, `"42"`, ETag(42))
+}
+
+func TestIfMatchVersion(t *testing.T) {
+	version := func(v int64) *int64 { return &v }
+
+	testCases := []struct {
+		name          string
+		header        string
+		expected      *int64
+		expectedError string
+	}{
+		{name: "Missing Header", header: "", expected: nil},
+		{name: "Any Version", header: "*", expected: nil},
+		{name: "Strong Tag", header: `"3"`, expected: version(3)},
+		{name: "Weak Tag", header: `W/"7"`, expected: version(7)},
+		{name: "Unquoted", header: "5", expected: version(5)},
+		{name: "Invalid Tag", header: `"abc"`, expectedError: "invalid If-Match header"},
+		{name: "Negative Version", header: `"-1"`, expectedError: "invalid If-Match header"},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			app := fiber.New()
+			ctx := app.AcquireCtx(&fasthttp.RequestCtx{})
+			defer app.ReleaseCtx(ctx)
+
+			if testCase.header != "" {
+				ctx.Request().Header.Set(fiber.HeaderIfMatch, testCase.header)
+			}
+
+			result, err := IfMatchVersion(ctx)
+			if testCase.expectedError != "" {
+				assert.EqualError(t, err, testCase.expectedError)
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, testCase.expected, result)
+		})
+	}
+}
+
+func TestMatchVersion(t *testing.T) {
+	version := func(v int64) *int64 { return &v }
+
+	testCases := []struct {
+		name     string
+		version  *int64
+		expected bson.M
+	}{
+		{name: "No Version", version: nil, expected: bson.M{"_id": 1}},
+		{name: "Version Zero", version: version(0), expected: bson.M{"_id": 1, "version": bson.M{"$in": bson.A{0, nil}}}},
+		{name: "Version", version: version(4), expected: bson.M{"_id": 1, "version": int64(4)}},
+	}
+
+	for _, testCase := range testCases {
+		t.Run(testCase.name, func(t *testing.T) {
+			assert.Equal(t, testCase.expected, MatchVersion(bson.M{"_id": 1}, testCase.version))
+		})
+	}
+}